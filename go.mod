@@ -2,11 +2,14 @@ module github.com/clj/kom2
 
 go 1.20
 
+require (
+	github.com/rs/zerolog v1.29.1
+	golang.org/x/exp v0.0.0-20230519143937-03e91628a987
+	golang.org/x/sync v0.2.0
+)
+
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
-	github.com/rs/zerolog v1.29.1 // indirect
-	golang.org/x/exp v0.0.0-20230519143937-03e91628a987 // indirect
-	golang.org/x/sync v0.2.0 // indirect
 	golang.org/x/sys v0.9.0 // indirect
 )
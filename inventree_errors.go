@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// An unsuccessful response from the InvenTree API
+type apiError struct {
+	status int
+	// The status text, or what the server said went wrong when it did
+	message string
+}
+
+func (e *apiError) Error() string { return fmt.Sprintf("HTTP %d: %s", e.status, e.message) }
+
+func (e *apiError) notFound() bool { return e.status == http.StatusNotFound }
+
+// Reads the error from a response, which InvenTree gives as JSON with either
+// a "detail" (from the REST framework) or an "error" (for server errors)
+func newApiError(response *http.Response) *apiError {
+	e := &apiError{status: response.StatusCode, message: http.StatusText(response.StatusCode)}
+
+	var body struct {
+		Detail string `json:"detail"`
+		Error  string `json:"error"`
+	}
+	if json.NewDecoder(io.LimitReader(response.Body, 1<<16)).Decode(&body) == nil {
+		if body.Detail != "" {
+			e.message = body.Detail
+		} else if body.Error != "" {
+			e.message = body.Error
+		}
+	}
+	return e
+}
+
+// Whether the error is InvenTree saying the resource doesn't exist
+func isNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.notFound()
+}
+
+// Describes an error talking to InvenTree. The SQLSTATE tells apart bad
+// credentials, timeouts and an unreachable or failing server, and the native
+// error is the HTTP status. Anything else, such as a response which can't be
+// decoded, gets sqlState.
+//
+// The HTTP timeout is the login timeout while connecting and the connection
+// timeout after that.
+func requestError(sqlState, message string, err error, connecting bool) *DriverError {
+	e := &DriverError{SqlState: sqlState, Message: message, Err: err}
+
+	var apiErr *apiError
+	var urlErr *url.Error
+	var netErr net.Error
+	var driverErr *DriverError
+	switch {
+	case errors.As(err, &driverErr):
+		// Already has its own SQLSTATE, such as for an unknown category
+		e.SqlState = driverErr.SqlState
+	case errors.As(err, &apiErr):
+		e.NativeError = apiErr.status
+		switch {
+		case apiErr.status == http.StatusUnauthorized || apiErr.status == http.StatusForbidden:
+			e.SqlState = "28000"
+		case apiErr.status >= 500:
+			e.SqlState = "08S01"
+		}
+	case errors.As(err, &urlErr) && urlErr.Timeout():
+		e.SqlState = "HYT01"
+		if connecting {
+			e.SqlState = "HYT00"
+		}
+	case errors.As(err, &urlErr) && errors.As(urlErr.Err, &netErr):
+		// Rather than a request which couldn't be made, such as for a
+		// server URL with an unsupported scheme
+		e.SqlState = "08S01"
+	}
+	return e
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// A page of a list, which InvenTree gives instead of the whole list when
+// asked for one with limit and offset, or always when it enforces pagination
+type listPage[T any] struct {
+	Count   int `json:"count"`
+	Results []T `json:"results"`
+}
+
+// Fetches a list, or a page of it, telling which of the two the server gave
+func apiPage[T any](c *connectionHandle, resource string, args map[string]string) (*listPage[T], bool, error) {
+	var data json.RawMessage
+	if err := c.apiGet(resource, args, &data); err != nil {
+		return nil, false, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	page := &listPage[T]{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := decoder.Decode(&page.Results); err != nil {
+			return nil, false, err
+		}
+		page.Count = len(page.Results)
+		return page, false, nil
+	}
+	if err := decoder.Decode(page); err != nil {
+		return nil, false, err
+	}
+	return page, true, nil
+}
+
+func pageArgs(args map[string]string, offset, limit int) map[string]string {
+	paged := make(map[string]string, len(args)+2)
+	for key, value := range args {
+		paged[key] = value
+	}
+	paged["offset"] = strconv.Itoa(offset)
+	paged["limit"] = strconv.Itoa(limit)
+	return paged
+}
+
+// Fetches all of a list, walking its pages if the server paginates it. The
+// pages are of the configured size, or else of whatever size the server
+// picks, which is also what's used when the server caps the size of pages
+// below the configured one. Once the first page tells how long the list is,
+// the others can be fetched in parallel.
+func apiList[T any](c *connectionHandle, resource string, args map[string]string, results *[]T) error {
+	pageSize := c.inventreeConfig.pageSize
+	firstArgs := args
+	if pageSize > 0 {
+		firstArgs = pageArgs(args, 0, pageSize)
+	}
+
+	page, paginated, err := apiPage[T](c, resource, firstArgs)
+	if err != nil {
+		return err
+	}
+	items := page.Results
+	if pageSize == 0 || len(items) < pageSize {
+		pageSize = len(items)
+	}
+	if !paginated || pageSize == 0 || len(items) >= page.Count {
+		*results = items
+		return nil
+	}
+
+	if c.inventreeConfig.parallelPages {
+		pages := make([][]T, (page.Count-len(items)+pageSize-1)/pageSize)
+		g := new(errgroup.Group)
+		g.SetLimit(maxConcurrentRequests)
+		for i := range pages {
+			i := i
+			g.Go(func() error {
+				page, _, err := apiPage[T](c, resource, pageArgs(args, len(items)+i*pageSize, pageSize))
+				if err != nil {
+					return err
+				}
+				pages[i] = page.Results
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return err
+		}
+		// Should the server give a short page anyway, the rest is walked
+		// one page at a time from there
+		for _, page := range pages {
+			items = append(items, page...)
+			if len(page) < pageSize {
+				break
+			}
+		}
+	}
+
+	// The list may change in between pages, so go by the latest count
+	for len(items) < page.Count {
+		if page, _, err = apiPage[T](c, resource, pageArgs(args, len(items), pageSize)); err != nil {
+			return err
+		}
+		if len(page.Results) == 0 {
+			break
+		}
+		items = append(items, page.Results...)
+	}
+	*results = items
+
+	return nil
+}
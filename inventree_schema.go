@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// #include <stdint.h>
+// #include <sqltypes.h>
+// #include <sql.h>
+// #include <sqlext.h>
+import "C"
+
+// The columns of the parts of a category as described by InvenTree, so that
+// they don't depend on the rows a statement happens to return
+type tableSchema struct {
+	// Sorted by name, like the columns of SELECT *
+	columns []*desc
+	byName  map[string]*desc
+}
+
+func (t *tableSchema) column(name string) *desc {
+	if t == nil {
+		return nil
+	}
+	return t.byName[name]
+}
+
+func (t *tableSchema) isDateTime(name string) bool {
+	column := t.column(name)
+	return column != nil && (column.dataType == C.SQL_TYPE_DATE || column.dataType == C.SQL_TYPE_TIMESTAMP)
+}
+
+func (t *tableSchema) add(column *desc) {
+	t.columns = append(t.columns, column)
+	t.byName[column.name] = column
+}
+
+// A field of the parts as described by the OPTIONS metadata of the InvenTree
+// API, which is that of the Django REST framework
+type schemaField struct {
+	Type      string `json:"type"`
+	Required  bool   `json:"required"`
+	WriteOnly bool   `json:"write_only"`
+	AllowNull *bool  `json:"allow_null"`
+	MaxLength *int   `json:"max_length"`
+}
+
+// Describes the column of a field, nil for fields which don't hold a single
+// value, such as lists and nested objects
+func (f *schemaField) describe(name string) *desc {
+	var value any
+	switch f.Type {
+	case "nested object", "list", "json", "dict":
+		return nil
+	case "integer", "related field":
+		value = json.Number("0")
+	case "float", "decimal":
+		value = floatNumber(0)
+	case "boolean":
+		value = false
+	case "date":
+		value = sqlDate("")
+	case "datetime":
+		value = sqlTimestamp("")
+	default:
+		value = ""
+	}
+
+	column := describeColumn(name, value)
+	if _, ok := value.(string); ok && f.MaxLength != nil && *f.MaxLength > 0 {
+		column.colSize = *f.MaxLength
+	}
+	switch {
+	case name == "pk":
+		column.nullable = C.SQL_NO_NULLS
+	case f.AllowNull != nil:
+		if !*f.AllowNull {
+			column.nullable = C.SQL_NO_NULLS
+		}
+	case f.Required:
+		column.nullable = C.SQL_NO_NULLS
+	}
+	return column
+}
+
+// Fetches the schema of a category, made up of the fields of the part list's
+// OPTIONS metadata, which InvenTree only gives to users allowed to add parts,
+// and a parameter column for each of the category's parameter templates.
+func (c *connectionHandle) fetchSchema(category string) (*tableSchema, error) {
+	categoryId, ok := c.categoryMapping[category]
+	if !ok {
+		return nil, fmt.Errorf("category does not exist in InvenTree: %s", category)
+	}
+
+	var options struct {
+		Actions map[string]json.RawMessage `json:"actions"`
+	}
+	if err := c.apiRequest("OPTIONS", "/api/part/", nil, &options); err != nil {
+		return nil, err
+	}
+	var fields map[string]*schemaField
+	for _, method := range []string{"POST", "PUT"} {
+		if err := json.Unmarshal(options.Actions[method], &fields); err == nil && len(fields) > 0 {
+			break
+		}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no field metadata for parts")
+	}
+
+	var templates []struct {
+		Template struct {
+			Name string `json:"name"`
+		} `json:"parameter_template_detail"`
+	}
+	args := map[string]string{"category": strconv.Itoa(categoryId)}
+	if err := apiList(c, "/api/part/category/parameters/", args, &templates); err != nil {
+		return nil, err
+	}
+
+	schema := &tableSchema{byName: make(map[string]*desc)}
+	for name, field := range fields {
+		if field.WriteOnly {
+			continue
+		}
+		if column := field.describe(name); column != nil {
+			schema.add(column)
+		}
+	}
+	for _, template := range templates {
+		name := "parameter." + template.Template.Name
+		if schema.column(name) == nil {
+			schema.add(describeColumn(name, ""))
+		}
+	}
+	sort.Slice(schema.columns, func(i, j int) bool { return schema.columns[i].name < schema.columns[j].name })
+
+	return schema, nil
+}
+
+// Gives the schema of a category, fetching it the first time. Categories
+// whose schema can't be fetched, for instance because the user may only view
+// parts, have none.
+func (c *connectionHandle) schema(category string) *tableSchema {
+	if schema, ok := c.schemas[category]; ok {
+		return schema
+	}
+
+	schema, err := c.fetchSchema(category)
+	if err != nil {
+		c.log.Warn().Err(err).Str("category", category).Msg("unable to fetch schema, describing columns by their values")
+	}
+	if c.schemas == nil {
+		c.schemas = make(map[string]*tableSchema)
+	}
+	c.schemas[category] = schema
+	return schema
+}
+
+// The primary key column of a category, pk, which is described as such even
+// when the category has no schema
+func (c *connectionHandle) primaryKey(category string) *desc {
+	if column := c.schema(category).column("pk"); column != nil {
+		return column
+	}
+	column := describeColumn("pk", json.Number("0"))
+	column.nullable = C.SQL_NO_NULLS
+	return column
+}
+
+// Whether InvenTree enforces unique IPNs, which it does unless its
+// PART_ALLOW_DUPLICATE_IPN setting allows duplicates. IPNs aren't taken to be
+// unique when the setting can't be fetched.
+func (c *connectionHandle) ipnsAreUnique() bool {
+	if c.uniqueIpns != nil {
+		return *c.uniqueIpns
+	}
+
+	var setting struct {
+		Value any `json:"value"`
+	}
+	unique := false
+	if err := c.apiGet("/api/settings/global/PART_ALLOW_DUPLICATE_IPN/", nil, &setting); err != nil {
+		c.log.Warn().Err(err).Msg("unable to fetch whether IPNs are unique")
+	} else {
+		switch value := setting.Value.(type) {
+		case bool:
+			unique = !value
+		case string:
+			unique = strings.EqualFold(value, "false")
+		}
+	}
+	c.uniqueIpns = &unique
+	return unique
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// #include <stdint.h>
+// #include <sqltypes.h>
+// #include <sql.h>
+// #include <sqlext.h>
+import "C"
+
+// The C type SQL_C_DEFAULT stands for, given the SQL type of a column
+func defaultCType(dataType C.short) C.SQLSMALLINT {
+	return typeOf(dataType).cType
+}
+
+func isDateTimeCType(cType C.SQLSMALLINT) bool {
+	switch cType {
+	case C.SQL_C_TYPE_DATE, C.SQL_C_DATE, C.SQL_C_TYPE_TIME, C.SQL_C_TIME, C.SQL_C_TYPE_TIMESTAMP, C.SQL_C_TIMESTAMP:
+		return true
+	}
+	return false
+}
+
+// The size of the C types with a fixed length, 0 for character and binary
+// data as well as types that aren't supported
+func cTypeSize(cType C.SQLSMALLINT) int {
+	if t, ok := integerCTypes[cType]; ok {
+		return t.size
+	}
+	switch cType {
+	case C.SQL_C_FLOAT:
+		return int(unsafe.Sizeof(C.SQLREAL(0)))
+	case C.SQL_C_DOUBLE:
+		return int(unsafe.Sizeof(C.SQLDOUBLE(0)))
+	case C.SQL_C_BIT:
+		return int(unsafe.Sizeof(C.SQLCHAR(0)))
+	case C.SQL_C_NUMERIC:
+		return int(unsafe.Sizeof(C.SQL_NUMERIC_STRUCT{}))
+	case C.SQL_C_TYPE_DATE, C.SQL_C_DATE:
+		return int(unsafe.Sizeof(C.SQL_DATE_STRUCT{}))
+	case C.SQL_C_TYPE_TIME, C.SQL_C_TIME:
+		return int(unsafe.Sizeof(C.SQL_TIME_STRUCT{}))
+	case C.SQL_C_TYPE_TIMESTAMP, C.SQL_C_TIMESTAMP:
+		return int(unsafe.Sizeof(C.SQL_TIMESTAMP_STRUCT{}))
+	case C.SQL_C_GUID:
+		return int(unsafe.Sizeof(C.SQLGUID{}))
+	}
+	return 0
+}
+
+type integerCType struct {
+	size int
+	min  int64
+	max  uint64
+}
+
+var integerCTypes = map[C.SQLSMALLINT]integerCType{
+	C.SQL_C_TINYINT:  {1, math.MinInt8, math.MaxInt8},
+	C.SQL_C_STINYINT: {1, math.MinInt8, math.MaxInt8},
+	C.SQL_C_UTINYINT: {1, 0, math.MaxUint8},
+	C.SQL_C_SHORT:    {2, math.MinInt16, math.MaxInt16},
+	C.SQL_C_SSHORT:   {2, math.MinInt16, math.MaxInt16},
+	C.SQL_C_USHORT:   {2, 0, math.MaxUint16},
+	C.SQL_C_LONG:     {4, math.MinInt32, math.MaxInt32},
+	C.SQL_C_SLONG:    {4, math.MinInt32, math.MaxInt32},
+	C.SQL_C_ULONG:    {4, 0, math.MaxUint32},
+	C.SQL_C_SBIGINT:  {8, math.MinInt64, math.MaxInt64},
+	C.SQL_C_UBIGINT:  {8, 0, math.MaxUint64},
+}
+
+// Stores the low size bytes of a value, which for negative numbers is their
+// two's complement
+func storeInteger(ptr unsafe.Pointer, size int, value uint64) {
+	switch size {
+	case 1:
+		*(*uint8)(ptr) = uint8(value)
+	case 2:
+		*(*uint16)(ptr) = uint16(value)
+	case 4:
+		*(*uint32)(ptr) = uint32(value)
+	default:
+		*(*uint64)(ptr) = value
+	}
+}
+
+func invalidCharacterValue(value any) *DriverError {
+	return &DriverError{SqlState: "22018", Message: fmt.Sprintf("Invalid character value for cast specification: %v", value)}
+}
+
+func numericValueOutOfRange(value any) *DriverError {
+	return &DriverError{SqlState: "22003", Message: fmt.Sprintf("Numeric value out of range: %v", value)}
+}
+
+func fractionalTruncation(value any) *DriverError {
+	return &DriverError{SqlState: "01S07", Message: fmt.Sprintf("Fractional truncation: %v", value)}
+}
+
+func stringDataRightTruncated() *DriverError {
+	return &DriverError{SqlState: "01004", Message: "String data, right truncated"}
+}
+
+// Converts a value to an integer C type, truncating any fraction with a
+// warning. Character values have to hold a number.
+func integerData(value any, t integerCType, ptr unsafe.Pointer) *DriverError {
+	if i, ok := toInteger(value); ok {
+		if i < t.min || (i > 0 && uint64(i) > t.max) {
+			return numericValueOutOfRange(value)
+		}
+		storeInteger(ptr, t.size, uint64(i))
+		return nil
+	}
+
+	f, ok := toNumber(value)
+	if !ok {
+		return invalidCharacterValue(value)
+	}
+	truncated := math.Trunc(f)
+	// Adding one to the maximum is exact where it matters, as the maximums
+	// that can't be represented exactly round up to a power of two
+	if math.IsNaN(f) || truncated < float64(t.min) || truncated >= float64(t.max)+1 {
+		return numericValueOutOfRange(value)
+	}
+	if truncated < 0 {
+		storeInteger(ptr, t.size, uint64(int64(truncated)))
+	} else {
+		storeInteger(ptr, t.size, uint64(truncated))
+	}
+	if truncated != f {
+		return fractionalTruncation(value)
+	}
+	return nil
+}
+
+func bitData(value any, ptr unsafe.Pointer) *DriverError {
+	f, ok := toNumber(value)
+	if !ok {
+		return invalidCharacterValue(value)
+	}
+	if math.IsNaN(f) || f < 0 || f >= 2 {
+		return numericValueOutOfRange(value)
+	}
+	*(*C.SQLCHAR)(ptr) = C.SQLCHAR(f)
+	if f != 0 && f != 1 {
+		return fractionalTruncation(value)
+	}
+	return nil
+}
+
+func floatData(value any, TargetType C.SQLSMALLINT, ptr unsafe.Pointer) *DriverError {
+	f, ok := toNumber(value)
+	if !ok {
+		return invalidCharacterValue(value)
+	}
+	if TargetType == C.SQL_C_FLOAT {
+		if math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+			return numericValueOutOfRange(value)
+		}
+		*(*C.SQLREAL)(ptr) = C.SQLREAL(f)
+		return nil
+	}
+	*(*C.SQLDOUBLE)(ptr) = C.SQLDOUBLE(f)
+	return nil
+}
+
+// Converts a value to a SQL_NUMERIC_STRUCT with the default scale of 0, whose
+// value is a little endian 128 bit integer
+func numericData(value any, ptr unsafe.Pointer) *DriverError {
+	number, ok := new(big.Rat).SetString(strings.TrimSpace(toString(value)))
+	if !ok {
+		return invalidCharacterValue(value)
+	}
+	integer := new(big.Int).Quo(number.Num(), number.Denom())
+	magnitude := new(big.Int).Abs(integer)
+	if magnitude.BitLen() > 8*C.SQL_MAX_NUMERIC_LEN {
+		return numericValueOutOfRange(value)
+	}
+
+	numeric := (*C.SQL_NUMERIC_STRUCT)(ptr)
+	numeric.precision = C.SQLCHAR(len(magnitude.String()))
+	numeric.scale = 0
+	numeric.sign = 1
+	if number.Sign() < 0 {
+		numeric.sign = 0
+	}
+	bytes := magnitude.Bytes()
+	for idx := range numeric.val {
+		numeric.val[idx] = 0
+		if idx < len(bytes) {
+			numeric.val[idx] = C.SQLCHAR(bytes[len(bytes)-1-idx])
+		}
+	}
+	if !number.IsInt() {
+		return fractionalTruncation(value)
+	}
+	return nil
+}
+
+// Layouts of the character representations of dates, times and timestamps
+var (
+	dateLayouts      = []string{"2006-01-02"}
+	timeLayouts      = []string{"15:04:05.999999999", "15:04"}
+	timestampLayouts = []string{"2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999", "2006-01-02 15:04"}
+)
+
+func parseTime(value string, layouts ...[]string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layouts := range layouts {
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Converts the character representation of a date, time or timestamp to one
+// of the date and time structures. Converting a timestamp to a date or a time
+// drops the other part, which is only truncation when it isn't zero.
+func dateTimeData(value any, TargetType C.SQLSMALLINT, ptr unsafe.Pointer) *DriverError {
+	var text string
+	switch value := value.(type) {
+	case string:
+		text = value
+	case sqlDate:
+		if TargetType == C.SQL_C_TYPE_TIME || TargetType == C.SQL_C_TIME {
+			return restrictedDataType(value, TargetType)
+		}
+		text = string(value)
+	case sqlTimestamp:
+		text = string(value)
+	default:
+		return restrictedDataType(value, TargetType)
+	}
+
+	switch TargetType {
+	case C.SQL_C_TYPE_DATE, C.SQL_C_DATE:
+		t, ok := parseTime(text, dateLayouts, timestampLayouts)
+		if !ok {
+			return invalidCharacterValue(value)
+		}
+		date := (*C.SQL_DATE_STRUCT)(ptr)
+		date.year, date.month, date.day = C.SQLSMALLINT(t.Year()), C.SQLUSMALLINT(t.Month()), C.SQLUSMALLINT(t.Day())
+		if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 || t.Nanosecond() != 0 {
+			return fractionalTruncation(value)
+		}
+	case C.SQL_C_TYPE_TIME, C.SQL_C_TIME:
+		t, ok := parseTime(text, timeLayouts, timestampLayouts)
+		if !ok {
+			return invalidCharacterValue(value)
+		}
+		tm := (*C.SQL_TIME_STRUCT)(ptr)
+		tm.hour, tm.minute, tm.second = C.SQLUSMALLINT(t.Hour()), C.SQLUSMALLINT(t.Minute()), C.SQLUSMALLINT(t.Second())
+		if t.Nanosecond() != 0 {
+			return fractionalTruncation(value)
+		}
+	default:
+		t, ok := parseTime(text, timestampLayouts, dateLayouts)
+		if !ok {
+			// Times are on the current date
+			t, ok = parseTime(text, timeLayouts)
+			if !ok {
+				return invalidCharacterValue(value)
+			}
+			now := time.Now()
+			t = time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		}
+		timestamp := (*C.SQL_TIMESTAMP_STRUCT)(ptr)
+		timestamp.year, timestamp.month, timestamp.day = C.SQLSMALLINT(t.Year()), C.SQLUSMALLINT(t.Month()), C.SQLUSMALLINT(t.Day())
+		timestamp.hour, timestamp.minute, timestamp.second = C.SQLUSMALLINT(t.Hour()), C.SQLUSMALLINT(t.Minute()), C.SQLUSMALLINT(t.Second())
+		timestamp.fraction = C.SQLUINTEGER(t.Nanosecond())
+	}
+	return nil
+}
+
+// Converts the character representation of a GUID, such as
+// 6F9619FF-8B86-D011-B42D-00C04FC964FF, to a SQLGUID
+func guidData(value any, ptr unsafe.Pointer) *DriverError {
+	text, ok := value.(string)
+	if !ok {
+		return restrictedDataType(value, C.SQL_C_GUID)
+	}
+	text = strings.Trim(strings.TrimSpace(text), "{}")
+	parts := strings.Split(text, "-")
+	if len(parts) != 5 || len(parts[0]) != 8 || len(parts[1]) != 4 || len(parts[2]) != 4 || len(parts[3]) != 4 || len(parts[4]) != 12 {
+		return invalidCharacterValue(value)
+	}
+	bytes, err := hex.DecodeString(strings.Join(parts, ""))
+	if err != nil {
+		return invalidCharacterValue(value)
+	}
+
+	// The first three fields are integers in native byte order, the last is
+	// an array of bytes
+	*(*uint32)(ptr) = uint32(bytes[0])<<24 | uint32(bytes[1])<<16 | uint32(bytes[2])<<8 | uint32(bytes[3])
+	*(*uint16)(unsafe.Add(ptr, 4)) = uint16(bytes[4])<<8 | uint16(bytes[5])
+	*(*uint16)(unsafe.Add(ptr, 6)) = uint16(bytes[6])<<8 | uint16(bytes[7])
+	copy(unsafe.Slice((*byte)(unsafe.Add(ptr, 8)), 8), bytes[8:])
+	return nil
+}
+
+// Copies a value as binary data, which for character values are their bytes
+// and can be retrieved in chunks like character data, and for other values
+// their representation as the C type of their SQL type
+func populateBinaryData(value any, TargetValuePtr C.SQLPOINTER, BufferLength C.SQLLEN, StrLen_or_IndPtr *C.SQLLEN, offset int) (int, *DriverError) {
+	var data []byte
+	switch value := value.(type) {
+	case string:
+		data = []byte(value)[offset:]
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			data = make([]byte, 8)
+			storeInteger(unsafe.Pointer(&data[0]), 8, uint64(i))
+		} else if f, err := value.Float64(); err == nil {
+			data = make([]byte, 8)
+			storeInteger(unsafe.Pointer(&data[0]), 8, math.Float64bits(f))
+		} else {
+			return offset, restrictedDataType(value, C.SQL_C_BINARY)
+		}
+	case float64:
+		data = make([]byte, 8)
+		storeInteger(unsafe.Pointer(&data[0]), 8, math.Float64bits(value))
+	case bool:
+		data = make([]byte, 4)
+		if value {
+			storeInteger(unsafe.Pointer(&data[0]), 4, 1)
+		}
+	default:
+		return offset, restrictedDataType(value, C.SQL_C_BINARY)
+	}
+
+	if StrLen_or_IndPtr != nil {
+		*StrLen_or_IndPtr = C.SQLLEN(len(data))
+	}
+	_, chunked := value.(string)
+	count := min(len(data), int(max(BufferLength, 0)))
+	if !chunked && count < len(data) {
+		return offset, numericValueOutOfRange(value)
+	}
+	if TargetValuePtr != nil && count > 0 {
+		copy(unsafe.Slice((*byte)(unsafe.Pointer(TargetValuePtr)), count), data[:count])
+	}
+	if count < len(data) {
+		return offset + count, stringDataRightTruncated()
+	}
+	return -1, nil
+}
+
+// Copies a value as character data into a buffer of BufferLength bytes, starting
+// offset bytes (for SQL_C_CHAR) or UTF-16 code units (for SQL_C_WCHAR) into it
+// so that long values can be retrieved in chunks. The length stored is that
+// of the rest of the value in bytes, not counting the null terminator. Gives
+// the offset of the next chunk and whether the value didn't fit.
+func populateCharData(value string, TargetType C.SQLSMALLINT,
+	TargetValuePtr C.SQLPOINTER, BufferLength C.SQLLEN, StrLen_or_IndPtr *C.SQLLEN, offset int,
+) (int, bool) {
+	if TargetType == C.SQL_C_WCHAR {
+		src := (*utf8stringToUTF16(value))[offset:]
+		count := 0
+		if BufferLength >= 2 {
+			count = min(len(src), int(BufferLength)/2-1)
+		}
+		// Surrogate pairs aren't split between chunks
+		if count > 0 && count < len(src) && utf16.IsSurrogate(rune(src[count-1])) && src[count-1] < 0xdc00 {
+			count -= 1
+		}
+		if TargetValuePtr != nil && BufferLength >= 2 {
+			dst := unsafe.Slice((*uint16)(unsafe.Pointer(TargetValuePtr)), count+1)
+			copy(dst, src[:count])
+			dst[count] = 0
+		}
+		if StrLen_or_IndPtr != nil {
+			*StrLen_or_IndPtr = C.SQLLEN(len(src) * 2)
+		}
+		return offset + count, count < len(src)
+	}
+
+	src := value[offset:]
+	count := 0
+	if BufferLength >= 1 {
+		count = min(len(src), int(BufferLength)-1)
+	}
+	if TargetValuePtr != nil && BufferLength >= 1 {
+		dst := unsafe.Slice((*byte)(unsafe.Pointer(TargetValuePtr)), count+1)
+		copy(dst, src[:count])
+		dst[count] = 0
+	}
+	if StrLen_or_IndPtr != nil {
+		*StrLen_or_IndPtr = C.SQLLEN(len(src))
+	}
+	return offset + count, count < len(src)
+}
+
+// Character representation of the values of the result set
+func charValue(value any) string {
+	switch value := value.(type) {
+	case json.Number:
+		return value.String()
+	case bool:
+		if value {
+			return "1"
+		}
+		return "0"
+	case float64:
+		return fmt.Sprintf("%g", value)
+	}
+	return toString(value)
+}
+
+// Numbers, dates and timestamps can't be retrieved in chunks. Only digits
+// after the decimal point, including the fraction of the seconds of a
+// timestamp, may be cut off, otherwise the buffer is too small for the value.
+func populateNumberCharData(value any, TargetType C.SQLSMALLINT,
+	TargetValuePtr C.SQLPOINTER, BufferLength C.SQLLEN, StrLen_or_IndPtr *C.SQLLEN,
+) (int, *DriverError) {
+	text := charValue(value)
+	characters := int(BufferLength) - 1
+	if TargetType == C.SQL_C_WCHAR {
+		characters = int(BufferLength)/2 - 1
+	}
+	if characters >= len(text) {
+		populateCharData(text, TargetType, TargetValuePtr, BufferLength, StrLen_or_IndPtr, 0)
+		return -1, nil
+	}
+
+	whole := text
+	if idx := strings.IndexAny(text, "."); idx >= 0 {
+		whole = text[:idx]
+	}
+	if strings.ContainsAny(text, "eE") || characters < len(whole) {
+		return 0, numericValueOutOfRange(value)
+	}
+	populateCharData(text, TargetType, TargetValuePtr, BufferLength, StrLen_or_IndPtr, 0)
+	return -1, stringDataRightTruncated()
+}
+
+func restrictedDataType(value any, TargetType C.SQLSMALLINT) *DriverError {
+	return &DriverError{SqlState: "07006", Message: fmt.Sprintf("Restricted data type attribute violation, cannot convert %v to %s", value, targetTypeToString(TargetType))}
+}
+
+// Converts a value of the result set to the C type TargetType, storing it in
+// the buffers of SQLBindCol or SQLGetData. Character and binary data can be
+// retrieved in chunks, starting offset into the value. Gives the offset of the
+// next chunk, -1 once the whole value has been retrieved, as well as any
+// warning or error. SQL_C_DEFAULT must already have been resolved.
+//
+// See: https://learn.microsoft.com/en-us/sql/odbc/reference/appendixes/converting-data-from-sql-to-c-data-types?view=sql-server-ver16
+func populateData(value any, TargetType C.SQLSMALLINT,
+	TargetValuePtr C.SQLPOINTER, BufferLength C.SQLLEN, StrLen_or_IndPtr *C.SQLLEN, offset int,
+) (int, *DriverError) {
+	if value == nil {
+		if StrLen_or_IndPtr == nil {
+			return offset, &DriverError{SqlState: "22002", Message: "Indicator variable required but not supplied"}
+		}
+		*StrLen_or_IndPtr = C.SQL_NULL_DATA
+		return -1, nil
+	}
+
+	switch TargetType {
+	case C.SQL_C_CHAR, C.SQL_C_WCHAR:
+		if _, ok := value.(string); !ok {
+			return populateNumberCharData(value, TargetType, TargetValuePtr, BufferLength, StrLen_or_IndPtr)
+		}
+		next, truncated := populateCharData(charValue(value), TargetType, TargetValuePtr, BufferLength, StrLen_or_IndPtr, offset)
+		if truncated {
+			return next, stringDataRightTruncated()
+		}
+		return -1, nil
+	case C.SQL_C_BINARY:
+		return populateBinaryData(value, TargetValuePtr, BufferLength, StrLen_or_IndPtr, offset)
+	}
+
+	// The remaining types have a fixed length, so their buffer length is
+	// ignored
+	size := cTypeSize(TargetType)
+	if size == 0 {
+		return offset, &DriverError{SqlState: "HY003", Message: fmt.Sprintf("Invalid application buffer type: %s", targetTypeToString(TargetType))}
+	}
+	ptr := unsafe.Pointer(TargetValuePtr)
+	if ptr == nil {
+		return offset, &DriverError{SqlState: "HY009", Message: "Invalid use of null pointer"}
+	}
+	// Dates and timestamps only convert to the date and time types
+	if isDateTime(value) && !isDateTimeCType(TargetType) {
+		return offset, restrictedDataType(value, TargetType)
+	}
+	var err *DriverError
+	switch TargetType {
+	case C.SQL_C_FLOAT, C.SQL_C_DOUBLE:
+		err = floatData(value, TargetType, ptr)
+	case C.SQL_C_BIT:
+		err = bitData(value, ptr)
+	case C.SQL_C_NUMERIC:
+		err = numericData(value, ptr)
+	case C.SQL_C_TYPE_DATE, C.SQL_C_DATE, C.SQL_C_TYPE_TIME, C.SQL_C_TIME, C.SQL_C_TYPE_TIMESTAMP, C.SQL_C_TIMESTAMP:
+		err = dateTimeData(value, TargetType, ptr)
+	case C.SQL_C_GUID:
+		err = guidData(value, ptr)
+	default:
+		err = integerData(value, integerCTypes[TargetType], ptr)
+	}
+
+	if err != nil && !err.isWarning() {
+		return offset, err
+	}
+	if StrLen_or_IndPtr != nil {
+		*StrLen_or_IndPtr = C.SQLLEN(size)
+	}
+	return -1, err
+}
@@ -90,12 +90,6 @@ func SQLBrowseConnectW(dbc C.SQLHDBC, connin *C.SQLWCHAR, conninLen C.SQLSMALLIN
 	return C.SQL_ERROR
 }
 
-//export SQLGetInfoW
-func SQLGetInfoW(dbc C.SQLHDBC, typ C.SQLUSMALLINT, val C.SQLPOINTER, valMax C.SQLSMALLINT,
-	valLen *C.SQLSMALLINT) C.SQLRETURN {
-	return C.SQL_ERROR
-}
-
 //export ConfigDSN
 func ConfigDSN(hwnd C.HWND, request C.WORD, driver C.LPCSTR, attribs C.LPCSTR) C.BOOL {
 	return 1
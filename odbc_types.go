@@ -0,0 +1,130 @@
+package main
+
+// #include <stdint.h>
+// #include <sqltypes.h>
+// #include <sql.h>
+// #include <sqlext.h>
+import "C"
+
+// A SQL type columns are described with, as SQLGetTypeInfo returns it
+type sqlType struct {
+	name     string
+	dataType C.short
+	// The verbose type and subcode, which differ from dataType for dates and
+	// times only
+	verboseType C.short
+	subcode     C.short
+	cType       C.SQLSMALLINT
+	// The size and decimal digits of columns which the schema doesn't give
+	// one for. See: http://www.ch-werner.de/sqliteodbc/html/sqlite3odbc_8c.html#a107
+	columnSize    int
+	decimalDigits int
+	// 0 when the display size is the column size
+	displaySize   int
+	numPrecRadix  int
+	maximumScale  int
+	literalPrefix string
+	literalSuffix string
+	createParams  string
+	caseSensitive bool
+	searchable    C.short
+}
+
+// Sorted by data type, which is the order SQLGetTypeInfo returns them in
+var sqlTypes = []*sqlType{
+	{
+		name: "BIGINT", dataType: C.SQL_BIGINT, verboseType: C.SQL_BIGINT, cType: C.SQL_C_SBIGINT,
+		columnSize: 20, displaySize: 20, numPrecRadix: 10, searchable: C.SQL_ALL_EXCEPT_LIKE,
+	},
+	{
+		name: "INTEGER", dataType: C.SQL_INTEGER, verboseType: C.SQL_INTEGER, cType: C.SQL_C_SLONG,
+		columnSize: 10, displaySize: 11, numPrecRadix: 10, searchable: C.SQL_ALL_EXCEPT_LIKE,
+	},
+	{
+		name: "SMALLINT", dataType: C.SQL_SMALLINT, verboseType: C.SQL_SMALLINT, cType: C.SQL_C_SSHORT,
+		columnSize: 5, displaySize: 6, numPrecRadix: 10, searchable: C.SQL_ALL_EXCEPT_LIKE,
+	},
+	{
+		name: "DOUBLE", dataType: C.SQL_DOUBLE, verboseType: C.SQL_DOUBLE, cType: C.SQL_C_DOUBLE,
+		columnSize: 53, displaySize: 24, numPrecRadix: 2, searchable: C.SQL_ALL_EXCEPT_LIKE,
+	},
+	{
+		name: "VARCHAR", dataType: C.SQL_VARCHAR, verboseType: C.SQL_VARCHAR, cType: C.SQL_C_CHAR,
+		columnSize: 255, literalPrefix: "'", literalSuffix: "'", createParams: "max length",
+		caseSensitive: true, searchable: C.SQL_SEARCHABLE,
+	},
+	{
+		name: "DATE", dataType: C.SQL_TYPE_DATE, verboseType: C.SQL_DATETIME, subcode: C.SQL_CODE_DATE, cType: C.SQL_C_TYPE_DATE,
+		columnSize: 10, literalPrefix: "{d '", literalSuffix: "'}", searchable: C.SQL_ALL_EXCEPT_LIKE,
+	},
+	{
+		name: "TIMESTAMP", dataType: C.SQL_TYPE_TIMESTAMP, verboseType: C.SQL_DATETIME, subcode: C.SQL_CODE_TIMESTAMP, cType: C.SQL_C_TYPE_TIMESTAMP,
+		columnSize: 26, decimalDigits: 6, maximumScale: 6, literalPrefix: "{ts '", literalSuffix: "'}", searchable: C.SQL_ALL_EXCEPT_LIKE,
+	},
+}
+
+// Gives the type of a data type, an empty one for a column without a type,
+// such as one of an empty result without a schema
+func typeOf(dataType C.short) *sqlType {
+	for _, t := range sqlTypes {
+		if t.dataType == dataType {
+			return t
+		}
+	}
+	return &sqlType{dataType: dataType, verboseType: dataType, cType: C.SQL_C_CHAR}
+}
+
+func (t *sqlType) isNumeric() bool {
+	return t.numPrecRadix != 0
+}
+
+// Whether the type has decimal digits, which are those of exact numeric types
+// and the fractional seconds of timestamps
+func (t *sqlType) hasScale() bool {
+	return t.numPrecRadix == 10 || t.dataType == C.SQL_TYPE_TIMESTAMP
+}
+
+func (d *desc) sqlType() *sqlType {
+	return typeOf(d.dataType)
+}
+
+func (d *desc) typeName() string {
+	return d.sqlType().name
+}
+
+// The verbose type and subcode of the column's type
+func (d *desc) verboseType() (C.short, C.short) {
+	t := d.sqlType()
+	return t.verboseType, t.subcode
+}
+
+// The column size, that of its type for columns which have none, such as
+// those of the results of catalog functions
+func (d *desc) size() int {
+	if d.colSize == 0 {
+		return d.sqlType().columnSize
+	}
+	return d.colSize
+}
+
+// The number of bytes the column takes when retrieved as its default C type
+func (d *desc) octetLength() int {
+	if size := cTypeSize(defaultCType(d.dataType)); size > 0 {
+		return size
+	}
+	return d.size()
+}
+
+// 10 when the column size of a numeric column is in digits, 2 when it is in
+// bits, and 0 for other columns
+func (d *desc) numPrecRadix() int {
+	return d.sqlType().numPrecRadix
+}
+
+// The maximum number of characters of the column's character representation
+func (d *desc) displaySize() int {
+	if size := d.sqlType().displaySize; size > 0 {
+		return size
+	}
+	return d.size()
+}
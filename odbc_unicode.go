@@ -0,0 +1,365 @@
+package main
+
+import (
+	"unicode/utf16"
+	"unsafe"
+)
+
+// #include <stdlib.h>
+// #include <stdint.h>
+// #include <sqltypes.h>
+// #include <sql.h>
+// #include <sqlext.h>
+import "C"
+
+// The wide (W) entry points, which driver managers call for Unicode
+// applications, convert their UTF-16 strings to and from UTF-8 and share the
+// narrow implementations.
+
+// Gives the UTF-8 string of a wide string argument of length characters, or
+// null terminated when length is SQL_NTS
+func fromUTF16[T C.int | C.short](str *C.SQLWCHAR, length T) string {
+	if str == nil {
+		return ""
+	}
+	count := int(length)
+	if length == C.SQL_NTS {
+		count = 0
+		for *(*uint16)(unsafe.Add(unsafe.Pointer(str), count*2)) != 0 {
+			count += 1
+		}
+	}
+	return string(utf16.Decode(unsafe.Slice((*uint16)(unsafe.Pointer(str)), count)))
+}
+
+// Gives a null terminated UTF-8 copy of a wide string argument, to be freed
+// with freeNarrow, or nil when the argument is nil, which the narrow entry
+// points tell apart from an empty string
+func toNarrow[T C.int | C.short](str *C.SQLWCHAR, length T) *C.SQLCHAR {
+	if str == nil {
+		return nil
+	}
+	return (*C.SQLCHAR)(unsafe.Pointer(C.CString(fromUTF16(str, length))))
+}
+
+func freeNarrow(str *C.SQLCHAR) {
+	C.free(unsafe.Pointer(str))
+}
+
+// Copies a string into a wide buffer of bufferLength characters, null
+// terminating it and not splitting surrogate pairs when it doesn't fit. Gives
+// the length of the whole string in characters and whether it was truncated.
+func copyStringToUTF16(dst *C.SQLWCHAR, src string, bufferLength int) (int, bool) {
+	units := *utf8stringToUTF16(src)
+	count := 0
+	if bufferLength > 0 {
+		count = min(len(units), bufferLength-1)
+	}
+	if count > 0 && count < len(units) && utf16.IsSurrogate(rune(units[count-1])) && units[count-1] < 0xdc00 {
+		count -= 1
+	}
+	if dst != nil && bufferLength > 0 {
+		buffer := unsafe.Slice((*uint16)(unsafe.Pointer(dst)), count+1)
+		copy(buffer, units[:count])
+		buffer[count] = 0
+	}
+	return len(units), count < len(units)
+}
+
+// Calls a narrow entry point which returns a string, with a buffer large
+// enough for all of it. The string is only valid when the call succeeds.
+func narrowString(call func(buffer *C.SQLCHAR, bufferLength C.SQLSMALLINT, lengthPtr *C.SQLSMALLINT) C.SQLRETURN) (string, C.SQLRETURN) {
+	size := 4096
+	for {
+		buffer := (*C.SQLCHAR)(C.malloc(C.size_t(size)))
+		var length C.SQLSMALLINT
+		ret := call(buffer, C.SQLSMALLINT(size), &length)
+		if ret != C.SQL_SUCCESS && ret != C.SQL_SUCCESS_WITH_INFO || int(length) < size {
+			str := ""
+			if (ret == C.SQL_SUCCESS || ret == C.SQL_SUCCESS_WITH_INFO) && length >= 0 {
+				str = C.GoStringN((*C.char)(unsafe.Pointer(buffer)), C.int(length))
+			}
+			C.free(unsafe.Pointer(buffer))
+			return str, ret
+		}
+		C.free(unsafe.Pointer(buffer))
+		size = int(length) + 1
+	}
+}
+
+// Returns a string from a wide entry point, warning the handle, if there is
+// one, when it had to be truncated
+func returnUTF16(handle any, ret C.SQLRETURN, truncated bool) C.SQLRETURN {
+	if ret != C.SQL_SUCCESS && ret != C.SQL_SUCCESS_WITH_INFO || !truncated {
+		return ret
+	}
+	if handle == nil {
+		return C.SQL_SUCCESS_WITH_INFO
+	}
+	return SetAndReturnWarning(handle, &DriverError{SqlState: "01004", Message: "String data, right truncated"})
+}
+
+//export SQLConnectW
+func SQLConnectW(ConnectionHandle C.SQLHDBC,
+	ServerName *C.SQLWCHAR, NameLength1 C.SQLSMALLINT,
+	UserName *C.SQLWCHAR, NameLength2 C.SQLSMALLINT,
+	Authentication *C.SQLWCHAR, NameLength3 C.SQLSMALLINT,
+) C.SQLRETURN {
+	serverName := toNarrow(ServerName, NameLength1)
+	defer freeNarrow(serverName)
+	userName := toNarrow(UserName, NameLength2)
+	defer freeNarrow(userName)
+	authentication := toNarrow(Authentication, NameLength3)
+	defer freeNarrow(authentication)
+
+	return SQLConnect(ConnectionHandle, serverName, C.SQL_NTS, userName, C.SQL_NTS, authentication, C.SQL_NTS)
+}
+
+//export SQLDriverConnectW
+func SQLDriverConnectW(
+	ConnectionHandle C.SQLHDBC,
+	WindowHandle C.SQLHWND,
+	InConnectionString *C.SQLWCHAR,
+	StringLength1 C.SQLSMALLINT,
+	OutConnectionString *C.SQLWCHAR,
+	BufferLength C.SQLSMALLINT,
+	StringLength2Ptr *C.SQLSMALLINT,
+	DriverCompletion C.SQLUSMALLINT,
+) C.SQLRETURN {
+	inConnectionString := toNarrow(InConnectionString, StringLength1)
+	defer freeNarrow(inConnectionString)
+
+	// Like SQLDriverConnect, the completed connection string isn't returned
+	return SQLDriverConnect(ConnectionHandle, WindowHandle, inConnectionString, C.SQL_NTS, nil, 0, nil, DriverCompletion)
+}
+
+//export SQLPrepareW
+func SQLPrepareW(StatementHandle C.SQLHSTMT, StatementText *C.SQLWCHAR, TextLength C.SQLINTEGER) C.SQLRETURN {
+	statementText := toNarrow(StatementText, TextLength)
+	defer freeNarrow(statementText)
+
+	return SQLPrepare(StatementHandle, statementText, C.SQL_NTS)
+}
+
+//export SQLExecDirectW
+func SQLExecDirectW(StatementHandle C.SQLHSTMT, StatementText *C.SQLWCHAR, TextLength C.SQLINTEGER) C.SQLRETURN {
+	statementText := toNarrow(StatementText, TextLength)
+	defer freeNarrow(statementText)
+
+	return SQLExecDirect(StatementHandle, statementText, C.SQL_NTS)
+}
+
+//export SQLTablesW
+func SQLTablesW(StatementHandle C.SQLHSTMT, CatalogName *C.SQLWCHAR, NameLength1 C.SQLSMALLINT, SchemaName *C.SQLWCHAR, NameLength2 C.SQLSMALLINT, TableName *C.SQLWCHAR, NameLength3 C.SQLSMALLINT, TableType *C.SQLWCHAR, NameLength4 C.SQLSMALLINT) C.SQLRETURN {
+	catalogName := toNarrow(CatalogName, NameLength1)
+	defer freeNarrow(catalogName)
+	schemaName := toNarrow(SchemaName, NameLength2)
+	defer freeNarrow(schemaName)
+	tableName := toNarrow(TableName, NameLength3)
+	defer freeNarrow(tableName)
+	tableType := toNarrow(TableType, NameLength4)
+	defer freeNarrow(tableType)
+
+	return SQLTables(StatementHandle, catalogName, C.SQL_NTS, schemaName, C.SQL_NTS, tableName, C.SQL_NTS, tableType, C.SQL_NTS)
+}
+
+//export SQLColumnsW
+func SQLColumnsW(StatementHandle C.SQLHSTMT, CatalogName *C.SQLWCHAR, NameLength1 C.SQLSMALLINT, SchemaName *C.SQLWCHAR, NameLength2 C.SQLSMALLINT, TableName *C.SQLWCHAR, NameLength3 C.SQLSMALLINT, ColumnName *C.SQLWCHAR, NameLength4 C.SQLSMALLINT) C.SQLRETURN {
+	catalogName := toNarrow(CatalogName, NameLength1)
+	defer freeNarrow(catalogName)
+	schemaName := toNarrow(SchemaName, NameLength2)
+	defer freeNarrow(schemaName)
+	tableName := toNarrow(TableName, NameLength3)
+	defer freeNarrow(tableName)
+	columnName := toNarrow(ColumnName, NameLength4)
+	defer freeNarrow(columnName)
+
+	return SQLColumns(StatementHandle, catalogName, C.SQL_NTS, schemaName, C.SQL_NTS, tableName, C.SQL_NTS, columnName, C.SQL_NTS)
+}
+
+//export SQLPrimaryKeysW
+func SQLPrimaryKeysW(StatementHandle C.SQLHSTMT, CatalogName *C.SQLWCHAR, NameLength1 C.SQLSMALLINT, SchemaName *C.SQLWCHAR, NameLength2 C.SQLSMALLINT, TableName *C.SQLWCHAR, NameLength3 C.SQLSMALLINT) C.SQLRETURN {
+	catalogName := toNarrow(CatalogName, NameLength1)
+	defer freeNarrow(catalogName)
+	schemaName := toNarrow(SchemaName, NameLength2)
+	defer freeNarrow(schemaName)
+	tableName := toNarrow(TableName, NameLength3)
+	defer freeNarrow(tableName)
+
+	return SQLPrimaryKeys(StatementHandle, catalogName, C.SQL_NTS, schemaName, C.SQL_NTS, tableName, C.SQL_NTS)
+}
+
+//export SQLStatisticsW
+func SQLStatisticsW(StatementHandle C.SQLHSTMT, CatalogName *C.SQLWCHAR, NameLength1 C.SQLSMALLINT, SchemaName *C.SQLWCHAR, NameLength2 C.SQLSMALLINT, TableName *C.SQLWCHAR, NameLength3 C.SQLSMALLINT, Unique C.SQLUSMALLINT, Reserved C.SQLUSMALLINT) C.SQLRETURN {
+	catalogName := toNarrow(CatalogName, NameLength1)
+	defer freeNarrow(catalogName)
+	schemaName := toNarrow(SchemaName, NameLength2)
+	defer freeNarrow(schemaName)
+	tableName := toNarrow(TableName, NameLength3)
+	defer freeNarrow(tableName)
+
+	return SQLStatistics(StatementHandle, catalogName, C.SQL_NTS, schemaName, C.SQL_NTS, tableName, C.SQL_NTS, Unique, Reserved)
+}
+
+//export SQLSpecialColumnsW
+func SQLSpecialColumnsW(StatementHandle C.SQLHSTMT, IdentifierType C.SQLUSMALLINT, CatalogName *C.SQLWCHAR, NameLength1 C.SQLSMALLINT, SchemaName *C.SQLWCHAR, NameLength2 C.SQLSMALLINT, TableName *C.SQLWCHAR, NameLength3 C.SQLSMALLINT, Scope C.SQLUSMALLINT, Nullable C.SQLUSMALLINT) C.SQLRETURN {
+	catalogName := toNarrow(CatalogName, NameLength1)
+	defer freeNarrow(catalogName)
+	schemaName := toNarrow(SchemaName, NameLength2)
+	defer freeNarrow(schemaName)
+	tableName := toNarrow(TableName, NameLength3)
+	defer freeNarrow(tableName)
+
+	return SQLSpecialColumns(StatementHandle, IdentifierType, catalogName, C.SQL_NTS, schemaName, C.SQL_NTS, tableName, C.SQL_NTS, Scope, Nullable)
+}
+
+//export SQLGetTypeInfoW
+func SQLGetTypeInfoW(StatementHandle C.SQLHSTMT, DataType C.SQLSMALLINT) C.SQLRETURN {
+	return SQLGetTypeInfo(StatementHandle, DataType)
+}
+
+//export SQLDescribeColW
+func SQLDescribeColW(StatementHandle C.SQLHSTMT, ColumnNumber C.SQLUSMALLINT, ColumnName *C.SQLWCHAR, BufferLength C.SQLSMALLINT,
+	NameLengthPtr *C.SQLSMALLINT, DataTypePtr *C.SQLSMALLINT, ColumnSizePtr *C.SQLULEN,
+	DecimalDigitsPtr *C.SQLSMALLINT, NullablePtr *C.SQLSMALLINT,
+) C.SQLRETURN {
+	name, ret := narrowString(func(buffer *C.SQLCHAR, bufferLength C.SQLSMALLINT, lengthPtr *C.SQLSMALLINT) C.SQLRETURN {
+		return SQLDescribeCol(StatementHandle, ColumnNumber, buffer, bufferLength, lengthPtr, DataTypePtr, ColumnSizePtr, DecimalDigitsPtr, NullablePtr)
+	})
+	if ret != C.SQL_SUCCESS && ret != C.SQL_SUCCESS_WITH_INFO {
+		return ret
+	}
+
+	length, truncated := copyStringToUTF16(ColumnName, name, int(BufferLength))
+	if NameLengthPtr != nil {
+		*NameLengthPtr = C.SQLSMALLINT(length)
+	}
+	return returnUTF16(resolveHandle(C.SQLHANDLE(StatementHandle)), ret, truncated)
+}
+
+//export SQLColAttributeW
+func SQLColAttributeW(
+	StatementHandle C.SQLHSTMT,
+	ColumnNumber C.SQLUSMALLINT,
+	FieldIdentifier C.SQLUSMALLINT,
+	CharacterAttributePtr C.SQLPOINTER,
+	BufferLength C.SQLSMALLINT,
+	StringLengthPtr *C.SQLSMALLINT,
+	NumericAttributePtr *C.SQLLEN,
+) C.SQLRETURN {
+	// Only string attributes set the length, numeric ones are returned as
+	// they are
+	isString := false
+	str, ret := narrowString(func(buffer *C.SQLCHAR, bufferLength C.SQLSMALLINT, lengthPtr *C.SQLSMALLINT) C.SQLRETURN {
+		*lengthPtr = -1
+		ret := SQLColAttribute(StatementHandle, ColumnNumber, FieldIdentifier, C.SQLPOINTER(buffer), bufferLength, lengthPtr, NumericAttributePtr)
+		isString = *lengthPtr >= 0
+		return ret
+	})
+	if !isString || (ret != C.SQL_SUCCESS && ret != C.SQL_SUCCESS_WITH_INFO) {
+		return ret
+	}
+
+	// The lengths of character attributes are in bytes
+	length, truncated := copyStringToUTF16((*C.SQLWCHAR)(CharacterAttributePtr), str, int(BufferLength)/2)
+	if StringLengthPtr != nil {
+		*StringLengthPtr = C.SQLSMALLINT(length * 2)
+	}
+	return returnUTF16(resolveHandle(C.SQLHANDLE(StatementHandle)), ret, truncated)
+}
+
+//export SQLGetDiagRecW
+func SQLGetDiagRecW(
+	HandleType C.SQLSMALLINT,
+	Handle C.SQLHANDLE,
+	RecNumber C.SQLSMALLINT,
+	SQLState *C.SQLWCHAR,
+	NativeErrorPtr *C.SQLINTEGER,
+	MessageText *C.SQLWCHAR,
+	BufferLength C.SQLSMALLINT,
+	TextLengthPtr *C.SQLSMALLINT,
+) C.SQLRETURN {
+	var sqlState [6]C.SQLCHAR
+	message, ret := narrowString(func(buffer *C.SQLCHAR, bufferLength C.SQLSMALLINT, lengthPtr *C.SQLSMALLINT) C.SQLRETURN {
+		return SQLGetDiagRec(HandleType, Handle, RecNumber, &sqlState[0], NativeErrorPtr, buffer, bufferLength, lengthPtr)
+	})
+	if SQLState != nil {
+		copyStringToUTF16(SQLState, C.GoString((*C.char)(unsafe.Pointer(&sqlState[0]))), len(sqlState))
+	}
+	if ret != C.SQL_SUCCESS && ret != C.SQL_SUCCESS_WITH_INFO {
+		if MessageText != nil && BufferLength > 0 {
+			*MessageText = 0
+		}
+		if TextLengthPtr != nil {
+			*TextLengthPtr = 0
+		}
+		return ret
+	}
+
+	length, truncated := copyStringToUTF16(MessageText, message, int(BufferLength))
+	if TextLengthPtr != nil {
+		*TextLengthPtr = C.SQLSMALLINT(length)
+	}
+	// Diagnostics aren't posted for retrieving diagnostics
+	return returnUTF16(nil, ret, truncated)
+}
+
+//export SQLGetDiagFieldW
+func SQLGetDiagFieldW(
+	HandleType C.SQLSMALLINT,
+	Handle C.SQLHANDLE,
+	RecNumber C.SQLSMALLINT,
+	DiagIdentifier C.SQLSMALLINT,
+	DiagInfoPtr C.SQLPOINTER,
+	BufferLength C.SQLSMALLINT,
+	StringLengthPtr *C.SQLSMALLINT,
+) C.SQLRETURN {
+	isString := false
+	str, ret := narrowString(func(buffer *C.SQLCHAR, bufferLength C.SQLSMALLINT, lengthPtr *C.SQLSMALLINT) C.SQLRETURN {
+		*lengthPtr = -1
+		ret := SQLGetDiagField(HandleType, Handle, RecNumber, DiagIdentifier, C.SQLPOINTER(buffer), bufferLength, lengthPtr)
+		isString = *lengthPtr >= 0
+		return ret
+	})
+	if !isString {
+		// Numeric fields were written to the narrow buffer, so they are
+		// retrieved again
+		return SQLGetDiagField(HandleType, Handle, RecNumber, DiagIdentifier, DiagInfoPtr, BufferLength, StringLengthPtr)
+	}
+	if ret != C.SQL_SUCCESS && ret != C.SQL_SUCCESS_WITH_INFO {
+		return ret
+	}
+
+	length, truncated := copyStringToUTF16((*C.SQLWCHAR)(DiagInfoPtr), str, int(BufferLength)/2)
+	if StringLengthPtr != nil {
+		*StringLengthPtr = C.SQLSMALLINT(length * 2)
+	}
+	return returnUTF16(nil, ret, truncated)
+}
+
+//export SQLGetInfoW
+func SQLGetInfoW(ConnectionHandle C.SQLHDBC, InfoType C.SQLUSMALLINT, InfoValuePtr C.SQLPOINTER,
+	BufferLength C.SQLSMALLINT, StringLengthPtr *C.SQLSMALLINT,
+) C.SQLRETURN {
+	isString := false
+	str, ret := narrowString(func(buffer *C.SQLCHAR, bufferLength C.SQLSMALLINT, lengthPtr *C.SQLSMALLINT) C.SQLRETURN {
+		*lengthPtr = -1
+		ret := SQLGetInfo(ConnectionHandle, InfoType, C.SQLPOINTER(buffer), bufferLength, lengthPtr)
+		isString = *lengthPtr >= 0
+		return ret
+	})
+	if !isString {
+		// Numeric information was written to the narrow buffer, so it is
+		// retrieved again
+		return SQLGetInfo(ConnectionHandle, InfoType, InfoValuePtr, BufferLength, StringLengthPtr)
+	}
+	if ret != C.SQL_SUCCESS && ret != C.SQL_SUCCESS_WITH_INFO {
+		return ret
+	}
+
+	length, truncated := copyStringToUTF16((*C.SQLWCHAR)(InfoValuePtr), str, int(BufferLength)/2)
+	if StringLengthPtr != nil {
+		*StringLengthPtr = C.SQLSMALLINT(length * 2)
+	}
+	return returnUTF16(resolveHandle(C.SQLHANDLE(ConnectionHandle)), ret, truncated)
+}
@@ -3,15 +3,16 @@ package main
 import (
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"reflect"
-	"regexp"
 	"runtime/cgo"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode/utf16"
 	"unsafe"
@@ -175,8 +176,9 @@ func (connHandle *connectionHandle) initConnection(dsn, connectionString, userNa
 	}
 
 	dsn = conStrArg("dsn", dsn)
+	connHandle.dsn = dsn
 
-	var fetchParametersStr, fetchMetadataStr, logFile, logFormat, logLevel, httpTimeout string
+	var fetchParametersStr, fetchMetadataStr, pageSizeStr, parallelPagesStr, logFile, logFormat, logLevel, httpTimeout string
 
 	// Config file is lowest priority
 	if dsn != "" {
@@ -184,8 +186,10 @@ func (connHandle *connectionHandle) initConnection(dsn, connectionString, userNa
 		connHandle.inventreeConfig.userName = SQLGetPrivateProfileString(dsn, "username", "", ".odbc.ini")
 		connHandle.inventreeConfig.password = SQLGetPrivateProfileString(dsn, "password", "", ".odbc.ini")
 		connHandle.inventreeConfig.apiToken = SQLGetPrivateProfileString(dsn, "apitoken", "", ".odbc.ini")
-		fetchParametersStr = SQLGetPrivateProfileString(dsn, "apitoken", "", ".odbc.ini")
-		fetchMetadataStr = SQLGetPrivateProfileString(dsn, "apitoken", "", ".odbc.ini")
+		fetchParametersStr = SQLGetPrivateProfileString(dsn, "fetchparameters", "", ".odbc.ini")
+		fetchMetadataStr = SQLGetPrivateProfileString(dsn, "fetchmetadata", "", ".odbc.ini")
+		pageSizeStr = SQLGetPrivateProfileString(dsn, "pagesize", "", ".odbc.ini")
+		parallelPagesStr = SQLGetPrivateProfileString(dsn, "parallelpages", "", ".odbc.ini")
 		logFile = SQLGetPrivateProfileString(dsn, "logfile", "", ".odbc.ini")
 		logFormat = SQLGetPrivateProfileString(dsn, "logformat", "", ".odbc.ini")
 		logLevel = SQLGetPrivateProfileString(dsn, "loglevel", "", ".odbc.ini")
@@ -200,7 +204,9 @@ func (connHandle *connectionHandle) initConnection(dsn, connectionString, userNa
 	connHandle.inventreeConfig.password = conStrArg("password", connHandle.inventreeConfig.password)
 	connHandle.inventreeConfig.apiToken = conStrArg("apitoken", connHandle.inventreeConfig.apiToken)
 	fetchParametersStr = conStrArg("fetchparameters", fetchParametersStr)
-	fetchMetadataStr = conStrArg("fetchparameters", fetchMetadataStr)
+	fetchMetadataStr = conStrArg("fetchmetadata", fetchMetadataStr)
+	pageSizeStr = conStrArg("pagesize", pageSizeStr)
+	parallelPagesStr = conStrArg("parallelpages", parallelPagesStr)
 	logFile = conStrArg("logfile", logFile)
 	logFormat = strings.ToLower(conStrArg("logformat", logFormat))
 	logLevel = strings.ToLower(conStrArg("loglevel", logLevel))
@@ -254,6 +260,23 @@ func (connHandle *connectionHandle) initConnection(dsn, connectionString, userNa
 		return SetAndReturnError(connHandle, &DriverError{SqlState: "08001", Message: "fetchMetadata accepts 'yes' or 'no"})
 	}
 
+	if pageSizeStr != "" {
+		pageSize, err := strconv.Atoi(pageSizeStr)
+		if err != nil || pageSize < 1 {
+			return SetAndReturnError(connHandle, &DriverError{SqlState: "08001", Message: "pageSize accepts a number greater than 0"})
+		}
+		connHandle.inventreeConfig.pageSize = pageSize
+	}
+
+	switch strings.ToLower(parallelPagesStr) {
+	case "yes":
+		connHandle.inventreeConfig.parallelPages = true
+	case "no", "":
+		connHandle.inventreeConfig.parallelPages = false
+	default:
+		return SetAndReturnError(connHandle, &DriverError{SqlState: "08001", Message: "parallelPages accepts 'yes' or 'no"})
+	}
+
 	if connHandle.inventreeConfig.server == "" {
 		return SetAndReturnError(connHandle, &DriverError{SqlState: "08001", Message: "No Server specified"})
 	}
@@ -266,13 +289,13 @@ func (connHandle *connectionHandle) initConnection(dsn, connectionString, userNa
 		var token string
 		token, err := connHandle.getApiToken(connHandle.inventreeConfig.userName, connHandle.inventreeConfig.password) // why pass these?
 		if err != nil {
-			return SetAndReturnError(connHandle, &DriverError{SqlState: "08001", Message: "Failed to fetch API Token", Err: err})
+			return SetAndReturnError(connHandle, requestError("08001", "Failed to fetch API Token", err, true))
 		}
 		connHandle.inventreeConfig.apiToken = token
 	}
 
 	if err := connHandle.updateCategoryMapping(); err != nil {
-		return SetAndReturnError(connHandle, &DriverError{SqlState: "08001", Message: "Error updating category list", Err: err})
+		return SetAndReturnError(connHandle, requestError("08001", "Error updating category list", err, true))
 	}
 
 	return C.SQL_SUCCESS
@@ -283,14 +306,29 @@ func resolveHandle(handle C.SQLHANDLE) any {
 	return cgo.Handle(handle).Value()
 }
 
+// The typed resolve functions are used by the ODBC functions on entry, so they
+// also start a new diagnostic area for the handle. SQLGetDiagRec and
+// SQLGetDiagField use resolveHandle, which leaves it alone.
+
+func resolveEnvironmentHandle(handle C.SQLHENV) *environmentHandle {
+	defer func() { recover() }()
+	e := cgo.Handle(handle).Value().(*environmentHandle)
+	e.clearDiagnostics()
+	return e
+}
+
 func resolveConnectionHandle(handle C.SQLHDBC) *connectionHandle {
 	defer func() { recover() }()
-	return cgo.Handle(handle).Value().(*connectionHandle)
+	c := cgo.Handle(handle).Value().(*connectionHandle)
+	c.clearDiagnostics()
+	return c
 }
 
 func resolveStatementHandle(handle C.SQLHSTMT) *statementHandle {
 	defer func() { recover() }()
-	return cgo.Handle(handle).Value().(*statementHandle)
+	s := cgo.Handle(handle).Value().(*statementHandle)
+	s.clearDiagnostics()
+	return s
 }
 
 //export SQLConnect
@@ -333,18 +371,38 @@ func SQLDriverConnect(
 }
 
 type DriverError struct {
-	SqlState    string
-	NativeError string
+	SqlState string
+	// The HTTP status of a failed request to InvenTree
+	NativeError int
 	Message     string
 	Err         error
+	// The row of the rowset, or the set of parameters, and the column, or
+	// the parameter, the diagnostic is about, counting from 1. Zero when it
+	// isn't about any in particular.
+	RowNumber    int
+	ColumnNumber int
 }
 
 func (e *DriverError) Error() string { return e.SqlState + ": " + e.Message }
 func (e *DriverError) Unwrap() error { return e.Err }
-func (e *DriverError) SetAndReturnError(handle errorInfo) C.SQLRETURN {
-	handle.errorInfo = e
 
-	return C.SQL_ERROR
+// SQLSTATEs of class 01 are warnings, which are returned with
+// SQL_SUCCESS_WITH_INFO
+func (e *DriverError) isWarning() bool { return strings.HasPrefix(e.SqlState, "01") }
+
+// The message of the diagnostic record, including the underlying error
+func (e *DriverError) messageText() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Gives a copy of the diagnostic about a row and column
+func (e *DriverError) at(row, column int) *DriverError {
+	located := *e
+	located.RowNumber, located.ColumnNumber = row, column
+	return &located
 }
 
 func getLogger(handle interface{}) zerolog.Logger {
@@ -360,7 +418,7 @@ func getLogger(handle interface{}) zerolog.Logger {
 	}
 }
 
-func SetError(handle interface{}, err *DriverError) zerolog.Logger {
+func diagnosticArea(handle interface{}) (*errorInfo, zerolog.Logger) {
 	switch h := handle.(type) {
 	// These are all the same really:
 	// case C.SQLHENV:
@@ -372,28 +430,106 @@ func SetError(handle interface{}, err *DriverError) zerolog.Logger {
 
 	switch h := handle.(type) {
 	case *environmentHandle:
-		h.errorInfo.errorInfo = err
-		return zerolog.Logger{}
+		return &h.errorInfo, zerolog.Logger{}
 	case *connectionHandle:
-		h.errorInfo.errorInfo = err
-		return h.log
+		return &h.errorInfo, h.log
 	case *statementHandle:
-		h.errorInfo.errorInfo = err
-		return h.log
+		return &h.errorInfo, h.log
 	default:
-		return zerolog.Logger{}
+		return nil, zerolog.Logger{}
+	}
+}
+
+// Posts a diagnostic record without returning, for functions which go on
+// after a warning or return several records
+func SetError(handle interface{}, err *DriverError) zerolog.Logger {
+	errorInfo, log := diagnosticArea(handle)
+	if errorInfo != nil {
+		errorInfo.post(err)
 	}
+	return log
 }
 
 func SetAndReturnError(handle interface{}, err *DriverError) C.SQLRETURN {
-	log := SetError(handle, err)
+	errorInfo, log := diagnosticArea(handle)
+	if errorInfo != nil {
+		errorInfo.post(err)
+		errorInfo.setReturnCode(C.SQL_ERROR)
+	}
 
 	log.Error().Err(err).Str("return", "SQL_ERROR").Send()
 	return C.SQL_ERROR
 }
 
+// Returns from a function which posted its diagnostic records with SetError
+func ReturnDiagnostics(handle interface{}, ret C.SQLRETURN) C.SQLRETURN {
+	errorInfo, log := diagnosticArea(handle)
+	if errorInfo != nil {
+		errorInfo.setReturnCode(ret)
+	}
+
+	if ret == C.SQL_ERROR {
+		log.Error().Str("return", "SQL_ERROR").Send()
+	} else {
+		log.Warn().Str("return", "SQL_SUCCESS_WITH_INFO").Send()
+	}
+	return ret
+}
+
+func SetAndReturnWarning(handle interface{}, err *DriverError) C.SQLRETURN {
+	errorInfo, log := diagnosticArea(handle)
+	if errorInfo != nil {
+		errorInfo.post(err)
+		errorInfo.setReturnCode(C.SQL_SUCCESS_WITH_INFO)
+	}
+
+	log.Warn().Err(err).Str("return", "SQL_SUCCESS_WITH_INFO").Send()
+	return C.SQL_SUCCESS_WITH_INFO
+}
+
+// The diagnostic area of a handle, with the records posted by the function
+// last called with it and what that function returned when it returned with
+// diagnostics. Errors are kept ahead of warnings, so the first record is the
+// most important one.
 type errorInfo struct {
-	errorInfo *DriverError
+	mutex       sync.Mutex
+	diagnostics []*DriverError
+	returnCode  C.SQLRETURN
+}
+
+func (e *errorInfo) clearDiagnostics() {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.diagnostics = nil
+	e.returnCode = C.SQL_SUCCESS
+}
+
+// Adds a record, which can be done concurrently while fetching parts
+func (e *errorInfo) post(err *DriverError) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	idx := len(e.diagnostics)
+	if !err.isWarning() {
+		idx = 0
+		for idx < len(e.diagnostics) && !e.diagnostics[idx].isWarning() {
+			idx += 1
+		}
+	}
+	e.diagnostics = append(e.diagnostics[:idx], append([]*DriverError{err}, e.diagnostics[idx:]...)...)
+}
+
+func (e *errorInfo) setReturnCode(ret C.SQLRETURN) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.returnCode = ret
+}
+
+// Gives the record RecNumber, counting from 1, or nil when there is none
+func (e *errorInfo) diagnostic(recNumber C.SQLSMALLINT) *DriverError {
+	if recNumber < 1 || int(recNumber) > len(e.diagnostics) {
+		return nil
+	}
+	return e.diagnostics[recNumber-1]
 }
 
 type logging struct {
@@ -423,7 +559,9 @@ type connectionHandle struct {
 
 	httpClient *http.Client
 
-	env             *environmentHandle
+	env *environmentHandle
+	// The data source connected to, if any, for SQL_DIAG_CONNECTION_NAME
+	dsn             string
 	inventreeConfig struct {
 		server          string
 		userName        string
@@ -431,13 +569,25 @@ type connectionHandle struct {
 		apiToken        string
 		fetchParameters bool
 		fetchMetadata   bool
-	}
+		// The number of items of each page of a list, zero for letting
+		// the server decide whether and how to paginate
+		pageSize      int
+		parallelPages bool
+	}
+	// Guards the API token, which is renewed by whichever request is first
+	// to have it rejected
+	tokenMutex      sync.Mutex
 	categoryMapping map[string]int
 	// This cache isn't ideal because it never expires. However, for KiCad
 	// I don't think it matters much, since KiCad will refresh its full list
 	// of parts before individual parts can be selected, which means this
 	// cache should be up to date with what parts can actually be selected.
 	ipnToPkMap map[string]any
+	// The schema of each category, nil for those whose schema couldn't be
+	// fetched, which are described by their data instead
+	schemas map[string]*tableSchema
+	// Whether InvenTree enforces unique IPNs, nil until it has been fetched
+	uniqueIpns *bool
 }
 
 func (c *connectionHandle) init(envHandle *environmentHandle) {
@@ -491,7 +641,7 @@ func (c *connectionHandle) getApiToken(userName, password string) (string, error
 		return "", err
 	}
 	if response.StatusCode != 200 {
-		return "", fmt.Errorf("unexpected status code %s", response.Status)
+		return "", newApiError(response)
 	}
 
 	type Token struct {
@@ -506,12 +656,45 @@ func (c *connectionHandle) getApiToken(userName, password string) (string, error
 	return val.Token, nil
 }
 
+func (c *connectionHandle) apiToken() string {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+	return c.inventreeConfig.apiToken
+}
+
+// Fetches a new API token after the server rejected the given one, such as
+// when it has expired or been rotated, unless another request already has.
+// That takes a username and password, without them the rejection is returned.
+func (c *connectionHandle) renewApiToken(rejected string, rejection error) (string, error) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+
+	if c.inventreeConfig.apiToken != rejected {
+		return c.inventreeConfig.apiToken, nil
+	}
+	if c.inventreeConfig.userName == "" || c.inventreeConfig.password == "" {
+		return "", fmt.Errorf("API token rejected, with no username and password to fetch another: %w", rejection)
+	}
+
+	token, err := c.getApiToken(c.inventreeConfig.userName, c.inventreeConfig.password)
+	if err != nil {
+		return "", err
+	}
+	c.log.Info().Msg("API token rejected, fetched a new one")
+	c.inventreeConfig.apiToken = token
+
+	return token, nil
+}
+
 func (c *connectionHandle) apiGet(resource string, args map[string]string, result any) error {
-	request, err := http.NewRequest("GET", c.inventreeConfig.server+resource, nil)
+	return c.apiRequest("GET", resource, args, result)
+}
+
+func (c *connectionHandle) apiRequest(method string, resource string, args map[string]string, result any) error {
+	request, err := http.NewRequest(method, c.inventreeConfig.server+resource, nil)
 	if err != nil {
 		return err
 	}
-	request.Header.Add("Authorization", fmt.Sprintf("Token %s", c.inventreeConfig.apiToken))
 	if args != nil {
 		q := request.URL.Query()
 		for key, val := range args {
@@ -519,12 +702,26 @@ func (c *connectionHandle) apiGet(resource string, args map[string]string, resul
 		}
 		request.URL.RawQuery = q.Encode()
 	}
+	token := c.apiToken()
+	request.Header.Set("Authorization", fmt.Sprintf("Token %s", token))
 	response, err := c.httpClient.Do(request)
 	if err != nil {
 		return err
 	}
+	// Retried once with a new token
+	if response.StatusCode == http.StatusUnauthorized {
+		rejection := newApiError(response)
+		response.Body.Close()
+		if token, err = c.renewApiToken(token, rejection); err != nil {
+			return err
+		}
+		request.Header.Set("Authorization", fmt.Sprintf("Token %s", token))
+		if response, err = c.httpClient.Do(request); err != nil {
+			return err
+		}
+	}
 	if response.StatusCode != 200 {
-		return fmt.Errorf("unexpected status code %s", response.Status)
+		return newApiError(response)
 	}
 
 	decoder := json.NewDecoder(response.Body)
@@ -543,7 +740,7 @@ func (c *connectionHandle) updateCategoryMapping() error {
 		Pathstring string `json:"pathstring"`
 	}
 	categories := []category{}
-	if err := c.apiGet("/api/part/category/", nil, &categories); err != nil {
+	if err := apiList(c, "/api/part/category/", nil, &categories); err != nil {
 		return err
 	}
 
@@ -563,14 +760,50 @@ func keys[K comparable, V any](m map[K]V) []K {
 }
 
 func (s *statementHandle) fetchAllParts(category string, parts *[]map[string]any) error {
+	return s.listParts(category, nil, parts)
+}
+
+// Lists the parts of a category, passing on any extra arguments such as
+// ordering, limit and offset to the server.
+func (s *statementHandle) listParts(category string, extraArgs map[string]string, parts *[]map[string]any) error {
 	args := make(map[string]string)
 	categoryId, ok := s.conn.categoryMapping[category]
 	if !ok {
 		return &DriverError{SqlState: "HY000", Message: fmt.Sprintf("Category does not exist in InvenTree: %s", category)}
 	}
 	args["category"] = strconv.Itoa(categoryId)
+	for key, value := range extraArgs {
+		args[key] = value
+	}
+
+	if _, ok := args["limit"]; !ok {
+		return apiList(s.conn, "/api/part/", args, parts)
+	}
+
+	// When given a limit the server paginates the results, though the page
+	// may be shorter than asked for when the server caps the size of pages
+	limit, _ := strconv.Atoi(args["limit"])
+	offset, _ := strconv.Atoi(args["offset"])
+	page, paginated, err := apiPage[map[string]any](s.conn, "/api/part/", args)
+	if err != nil {
+		return err
+	}
+	*parts = page.Results
+	if !paginated {
+		// The server ignored the limit and gave the whole list, which the
+		// limit is then applied to here, as the statement won't apply it
+		*parts = (*parts)[min(offset, len(*parts)):]
+		*parts = (*parts)[:min(limit, len(*parts))]
+		return nil
+	}
+	for len(page.Results) > 0 && len(*parts) < limit && offset+len(*parts) < page.Count {
+		if page, _, err = apiPage[map[string]any](s.conn, "/api/part/", pageArgs(args, offset+len(*parts), limit-len(*parts))); err != nil {
+			return err
+		}
+		*parts = append(*parts, page.Results...)
+	}
 
-	return s.conn.apiGet("/api/part/", args, parts)
+	return nil
 }
 
 func mangleParameters(params []map[string]any) map[string]any {
@@ -585,7 +818,7 @@ func mangleParameters(params []map[string]any) map[string]any {
 	return result
 }
 
-func (s *statementHandle) fetchPart(category string, column string, value any, parts *[]map[string]any) error {
+func (s *statementHandle) fetchPart(category string, column string, value any, withParameters, withMetadata bool, parts *[]map[string]any) error {
 	var part map[string]any
 	var partMetadata map[string]any
 	var partParameters map[string]any
@@ -620,8 +853,17 @@ func (s *statementHandle) fetchPart(category string, column string, value any, p
 		return fmt.Errorf("invalid filter column: %s", column)
 	}
 
+	// A part which doesn't exist is no row, like with the IPN, and its
+	// metadata and parameters are missing too
+	var notFound bool
+	var parametersErr error
+
 	getPart := func() error {
 		if err := s.conn.apiGet(fmt.Sprintf("/api/part/%v/", pkValue), nil, &part); err != nil {
+			if isNotFound(err) {
+				notFound = true
+				return nil
+			}
 			return err
 		}
 		return nil
@@ -631,7 +873,7 @@ func (s *statementHandle) fetchPart(category string, column string, value any, p
 
 	g.Go(getPart)
 
-	if s.conn.inventreeConfig.fetchMetadata {
+	if withMetadata {
 		g.Go(func() error {
 			if err := s.conn.apiGet(fmt.Sprintf("/api/part/%v/metadata/", pkValue), nil, &partMetadata); err != nil {
 				return err
@@ -641,15 +883,16 @@ func (s *statementHandle) fetchPart(category string, column string, value any, p
 		})
 	}
 
-	if s.conn.inventreeConfig.fetchParameters {
+	if withParameters {
 		g.Go(func() error {
 			var rawPartParameters []map[string]any
 			args := make(map[string]string)
 			value, _ := Convert(pkValue, "string") // maybe just sprintf?
 			args["part"] = value.(string)
 
-			if err := s.conn.apiGet("/api/part/parameter/", args, &rawPartParameters); err != nil {
-				return err
+			if err := apiList(s.conn, "/api/part/parameter/", args, &rawPartParameters); err != nil {
+				parametersErr = err
+				return nil
 			}
 
 			partParameters = mangleParameters(rawPartParameters)
@@ -658,96 +901,344 @@ func (s *statementHandle) fetchPart(category string, column string, value any, p
 		})
 	}
 
-	if err := g.Wait(); err != nil {
+	err := g.Wait()
+	if notFound {
+		return nil
+	}
+	if err != nil {
 		return err
 	}
+	if parametersErr != nil {
+		s.parametersUnavailable(pkValue, parametersErr)
+	}
 
-	var flatten func(string, map[string]any)
-	flatten = func(path string, data map[string]any) {
-		for key, value := range data {
-			if path == "" {
-				key = key
-			} else {
-				key = path + "." + key
-			}
+	if partMetadata != nil {
+		flatten(part, "", partMetadata)
+	}
+	for key, parameter := range partParameters {
+		part["parameter."+key] = parameter
+	}
+	*parts = append(*parts, part)
 
-			if value == nil {
-				part[key] = value // ???
-				continue
-			}
+	return nil
+}
 
-			switch reflect.TypeOf(value).Kind().String() {
-			case "array":
-				continue
-			case "map":
-				flatten(key, value.(map[string]any))
-			default:
-				part[key] = value
+func flatten(part map[string]any, path string, data map[string]any) {
+	for key, value := range data {
+		if path != "" {
+			key = path + "." + key
+		}
+
+		if value == nil {
+			part[key] = value // ???
+			continue
+		}
+
+		switch reflect.TypeOf(value).Kind().String() {
+		case "array":
+			continue
+		case "map":
+			flatten(part, key, value.(map[string]any))
+		default:
+			part[key] = value
+		}
+	}
+}
+
+// Parts whose parameters couldn't be fetched are still returned, without their
+// parameters, with a warning for each of them
+func (s *statementHandle) parametersUnavailable(pk any, err error) {
+	warning := &DriverError{SqlState: "01000", Message: fmt.Sprintf("Unable to fetch the parameters of part %v", pk), Err: err}
+	log := SetError(s, warning)
+	log.Warn().Err(warning).Send()
+}
+
+// Listing parts doesn't include their parameters. When the parts are the
+// whole category, fetch the parameters of the category in one go rather than
+// making a request per part like fetchPart does. Otherwise, such as when a
+// limit has cut the listing short, only fetch those of the parts at hand.
+func (s *statementHandle) fetchAllParameters(category string, parts []map[string]any, wholeCategory bool) {
+	var partParameters map[string][]map[string]any
+	if wholeCategory {
+		partParameters = s.fetchCategoryParameters(category, parts)
+	} else {
+		partParameters = s.fetchPartParameters(parts)
+	}
+
+	for _, part := range parts {
+		for key, parameter := range mangleParameters(partParameters[fmt.Sprint(part["pk"])]) {
+			part["parameter."+key] = parameter
+		}
+	}
+}
+
+// Gives the parameters of a category by the pk of their part
+func (s *statementHandle) fetchCategoryParameters(category string, parts []map[string]any) map[string][]map[string]any {
+	var rawParameters []map[string]any
+	args := make(map[string]string)
+	args["category"] = strconv.Itoa(s.conn.categoryMapping[category])
+
+	if err := apiList(s.conn, "/api/part/parameter/", args, &rawParameters); err != nil {
+		for _, part := range parts {
+			s.parametersUnavailable(part["pk"], err)
+		}
+		return nil
+	}
+
+	// Parameters are matched up by part, so this is correct even if the
+	// server doesn't support filtering parameters by category
+	partParameters := make(map[string][]map[string]any)
+	for _, parameter := range rawParameters {
+		pk := fmt.Sprint(parameter["part"])
+		partParameters[pk] = append(partParameters[pk], parameter)
+	}
+	return partParameters
+}
+
+// Gives the parameters of each of the parts by its pk
+func (s *statementHandle) fetchPartParameters(parts []map[string]any) map[string][]map[string]any {
+	rawParameters := make([][]map[string]any, len(parts))
+	errs := make([]error, len(parts))
+	g := new(errgroup.Group)
+	g.SetLimit(maxConcurrentRequests)
+	for i, part := range parts {
+		i, part := i, part
+		g.Go(func() error {
+			args := map[string]string{"part": fmt.Sprint(part["pk"])}
+			errs[i] = apiList(s.conn, "/api/part/parameter/", args, &rawParameters[i])
+			return nil
+		})
+	}
+	g.Wait()
+
+	// The warnings are added here so that they are in the order of the parts
+	partParameters := make(map[string][]map[string]any)
+	for i, part := range parts {
+		if errs[i] != nil {
+			s.parametersUnavailable(part["pk"], errs[i])
+			continue
+		}
+		partParameters[fmt.Sprint(part["pk"])] = rawParameters[i]
+	}
+	return partParameters
+}
+
+const maxConcurrentRequests = 8
+
+func (s *statementHandle) fetchAllMetadata(parts []map[string]any) error {
+	g := new(errgroup.Group)
+	g.SetLimit(maxConcurrentRequests)
+
+	for _, part := range parts {
+		part := part
+		g.Go(func() error {
+			var partMetadata map[string]any
+			if err := s.conn.apiGet(fmt.Sprintf("/api/part/%v/metadata/", part["pk"]), nil, &partMetadata); err != nil {
+				return err
+			}
+			if partMetadata != nil {
+				flatten(part, "", partMetadata)
 			}
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// Fetches several parts by pk or IPN, with their parameters and metadata like
+// fetchPart would, but using a single listing of the category rather than
+// fetching each part separately.
+func (s *statementHandle) fetchParts(category string, column string, values []string, withParameters, withMetadata bool) ([]map[string]any, error) {
+	var allParts []map[string]any
+	if err := s.fetchAllParts(category, &allParts); err != nil {
+		return nil, err
+	}
+	if err := s.conn.updateIpnToPkMap(&allParts); err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool)
+	for _, value := range values {
+		wanted[value] = true
+	}
+	parts := []map[string]any{}
+	for _, part := range allParts {
+		if part[column] != nil && wanted[toString(part[column])] {
+			parts = append(parts, part)
 		}
 	}
 
-	if partMetadata != nil {
-		flatten("", partMetadata)
+	if err := s.fetchDetails(category, parts, false, withParameters, withMetadata); err != nil {
+		return nil, err
 	}
-	for key, parameter := range partParameters {
-		part["parameter."+key] = parameter
+
+	return parts, nil
+}
+
+// Fetches the parameters and metadata of the parts, which are the whole
+// category when wholeCategory is set
+func (s *statementHandle) fetchDetails(category string, parts []map[string]any, wholeCategory, withParameters, withMetadata bool) error {
+	if len(parts) > 0 && withParameters {
+		s.fetchAllParameters(category, parts, wholeCategory)
+	}
+	if len(parts) > 0 && withMetadata {
+		if err := s.fetchAllMetadata(parts); err != nil {
+			return err
+		}
 	}
-	*parts = append(*parts, part)
 
 	return nil
 }
 
-func (s *statementHandle) populateColDesc(data *[]map[string]any) {
-	columns := make(map[string]*desc)
-
-	for _, row := range *data {
-		for key := range row {
-			if _, ok := columns[key]; !ok {
-				size := 0 // See: http://www.ch-werner.de/sqliteodbc/html/sqlite3odbc_8c.html#a107
-				var dataType C.short
-				switch value := row[key].(type) {
-				case json.Number:
-					if _, err := value.Int64(); err == nil {
-						dataType = C.SQL_BIGINT
-						size = 20
-					} else if _, err := value.Float64(); err == nil {
-						dataType = C.SQL_DOUBLE
-						size = 54
-					}
-				case string:
-					dataType = C.SQL_VARCHAR
-					size = 255
-				case float64:
-					dataType = C.SQL_DOUBLE
-					size = 54
-				case bool:
-					dataType = C.SQL_INTEGER
-					size = 10
-				}
-				columns[key] = &desc{name: key, dataType: dataType, colSize: size, nullable: C.SQL_NULLABLE}
+// Fetches the parts a statement could match, using a direct lookup when the
+// condition pins down pk or IPN and listing the category otherwise. Parameters
+// and metadata are only fetched when the statement uses them.
+func (s *statementHandle) fetchRows(ctx *evalContext) ([]map[string]any, error) {
+	table := s.statement.from.name
+	var parts []map[string]any
+
+	withParameters, withMetadata := s.statement.needsDetails()
+	withParameters = withParameters && s.conn.inventreeConfig.fetchParameters
+	withMetadata = withMetadata && s.conn.inventreeConfig.fetchMetadata
+
+	if column, values, ok := ctx.keyLookup(); ok {
+		if len(values) == 1 {
+			if err := s.fetchPart(table, column, values[0], withParameters, withMetadata, &parts); err != nil {
+				return nil, err
 			}
+			return parts, nil
+		}
+		return s.fetchParts(table, column, values, withParameters, withMetadata)
+	}
+
+	// Let the server do the ordering, and then also the limiting if there is
+	// no condition which would need to be applied before limiting
+	args := make(map[string]string)
+	if ordering, ok := ctx.ordering(); ok {
+		args["ordering"] = ordering
+		ctx.orderPushedDown = true
+	}
+	stmt := s.statement
+	if stmt.where == nil && stmt.limit != nil && !stmt.isAggregate() && !stmt.distinct && (stmt.orderBy == nil || ctx.orderPushedDown) {
+		args["limit"] = strconv.FormatInt(*stmt.limit, 10)
+		if stmt.offset != nil {
+			args["offset"] = strconv.FormatInt(*stmt.offset, 10)
+		}
+		ctx.limitPushedDown = true
+	}
+
+	if err := s.listParts(table, args, &parts); err != nil {
+		return nil, err
+	}
+	// A partial listing would leave out IPNs that fetchPart would then
+	// not be able to find
+	if !ctx.limitPushedDown {
+		if err := s.conn.updateIpnToPkMap(&parts); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.fetchDetails(table, parts, !ctx.limitPushedDown, withParameters, withMetadata); err != nil {
+		return nil, err
+	}
+
+	return parts, nil
+}
+
+// Fetches the rows of each table of the FROM clause. Joined tables are listed
+// in full, once per distinct table, with their columns qualified by the alias
+// or name of the table.
+func (s *statementHandle) fetchTables(ctx *evalContext) ([][]map[string]any, error) {
+	if len(s.statement.joins) == 0 {
+		parts, err := s.fetchRows(ctx)
+		if err != nil {
+			return nil, err
 		}
+		typeDates(parts, s.conn.schema(s.statement.from.name))
+		return [][]map[string]any{parts}, nil
 	}
 
-	s.columnNames = keys(columns)
-	sort.Strings(s.columnNames)
+	withParameters, withMetadata := s.statement.needsDetails()
+	withParameters = withParameters && s.conn.inventreeConfig.fetchParameters
+	withMetadata = withMetadata && s.conn.inventreeConfig.fetchMetadata
 
-	for _, name := range s.columnNames {
-		s.def = append(s.def, columns[name])
+	fetched := make(map[string][]map[string]any)
+	var tables [][]map[string]any
+	for _, table := range s.statement.tables() {
+		parts, ok := fetched[table.name]
+		if !ok {
+			if err := s.fetchAllParts(table.name, &parts); err != nil {
+				return nil, err
+			}
+			if err := s.conn.updateIpnToPkMap(&parts); err != nil {
+				return nil, err
+			}
+			if err := s.fetchDetails(table.name, parts, true, withParameters, withMetadata); err != nil {
+				return nil, err
+			}
+			typeDates(parts, s.conn.schema(table.name))
+			fetched[table.name] = parts
+		}
+		tables = append(tables, qualifyColumns(table.qualifier(), parts))
 	}
+
+	return tables, nil
 }
 
-func (s *statementHandle) populateData(data *[]map[string]any) {
-	for _, row := range *data {
-		rowData := make([]any, len(s.def))
-		for idx, colName := range s.columnNames {
-			if value, ok := row[colName]; ok {
-				rowData[idx] = value
+func describeColumn(name string, value any) *desc {
+	var dataType C.short
+	switch value := value.(type) {
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			dataType = C.SQL_BIGINT
+		} else if _, err := value.Float64(); err == nil {
+			dataType = C.SQL_DOUBLE
+		}
+	case string:
+		dataType = C.SQL_VARCHAR
+	case float64:
+		dataType = C.SQL_DOUBLE
+	case bool:
+		dataType = C.SQL_INTEGER
+	case sqlDate:
+		dataType = C.SQL_TYPE_DATE
+	case sqlTimestamp:
+		dataType = C.SQL_TYPE_TIMESTAMP
+	}
+	t := typeOf(dataType)
+	return &desc{name: name, dataType: dataType, colSize: t.columnSize, decimalDigits: t.decimalDigits, nullable: C.SQL_NULLABLE}
+}
+
+// Sets up the result set, describing each column as the schema describes it,
+// otherwise by its type hint or the type of its first non-NULL value, and
+// as VARCHAR when it has neither
+func (s *statementHandle) populateResult(names []string, columns []*desc, hints []any, data [][]any) {
+	s.columnNames = names
+	s.def = nil
+	for idx, name := range names {
+		if idx < len(columns) && columns[idx] != nil {
+			column := *columns[idx]
+			column.name = name
+			s.def = append(s.def, &column)
+			continue
+		}
+		var value any
+		if idx < len(hints) {
+			value = hints[idx]
+		}
+		for _, row := range data {
+			if value != nil {
+				break
 			}
+			value = row[idx]
 		}
-		s.data = append(s.data, rowData)
+		if value == nil {
+			// Columns which are always NULL, such as missing parameters
+			value = ""
+		}
+		s.def = append(s.def, describeColumn(name, value))
 	}
+	s.data = data
 }
 
 type bind struct {
@@ -759,8 +1250,92 @@ type bind struct {
 
 type param struct {
 	ValueType         C.SQLSMALLINT
+	ParameterType     C.SQLSMALLINT
 	ParameterValuePtr C.SQLPOINTER
 	BufferLength      C.SQLLEN
+	StrLen_or_IndPtr  *C.SQLLEN
+}
+
+// The size of one value of a C type in a column-wise bound array, which for
+// character and binary types is the length of the buffer of each value
+func elementSize(cType C.SQLSMALLINT, bufferLength C.SQLLEN) int {
+	if size := cTypeSize(cType); size > 0 {
+		return size
+	}
+	return int(bufferLength)
+}
+
+// The size of one value in a column-wise bound parameter array
+func (p *param) elementSize() int {
+	return elementSize(p.ValueType, p.BufferLength)
+}
+
+// Reads the value of a bound parameter for the given set of parameters, which
+// is done on every execution as the application is free to change the bound
+// buffers in between. Sets other than the first only exist for parameter
+// arrays, which are bound either column-wise or row-wise depending on
+// bindType.
+func (p *param) value(set int, bindType C.SQLULEN) (any, *DriverError) {
+	valuePtr := unsafe.Pointer(p.ParameterValuePtr)
+	indPtr := p.StrLen_or_IndPtr
+	if set > 0 {
+		valueOffset, indOffset := set*int(bindType), set*int(bindType)
+		if bindType == C.SQL_PARAM_BIND_BY_COLUMN {
+			valueOffset, indOffset = set*p.elementSize(), set*int(unsafe.Sizeof(C.SQLLEN(0)))
+		}
+		if valuePtr != nil {
+			valuePtr = unsafe.Add(valuePtr, valueOffset)
+		}
+		if indPtr != nil {
+			indPtr = (*C.SQLLEN)(unsafe.Add(unsafe.Pointer(indPtr), indOffset))
+		}
+	}
+
+	length := C.SQLLEN(C.SQL_NTS)
+	if indPtr != nil {
+		length = *indPtr
+	}
+	switch {
+	case length == C.SQL_NULL_DATA || valuePtr == nil:
+		return nil, nil
+	case length == C.SQL_DATA_AT_EXEC || length <= C.SQL_LEN_DATA_AT_EXEC_OFFSET:
+		return nil, &DriverError{SqlState: "HYC00", Message: "Data at execution parameters are not supported"}
+	}
+
+	switch p.ValueType {
+	case C.SQL_C_CHAR:
+		if length == C.SQL_NTS {
+			return C.GoString((*C.char)(valuePtr)), nil
+		}
+		return C.GoStringN((*C.char)(valuePtr), C.int(length)), nil
+	case C.SQL_C_WCHAR:
+		var count int
+		if length == C.SQL_NTS {
+			for *(*uint16)(unsafe.Add(valuePtr, count*2)) != 0 {
+				count += 1
+			}
+		} else {
+			count = int(length) / 2
+		}
+		return string(utf16.Decode(unsafe.Slice((*uint16)(valuePtr), count))), nil
+	case C.SQL_C_LONG, C.SQL_C_SLONG:
+		return json.Number(strconv.FormatInt(int64(*(*C.SQLINTEGER)(valuePtr)), 10)), nil
+	case C.SQL_C_SBIGINT:
+		return json.Number(strconv.FormatInt(int64(*(*C.SQLBIGINT)(valuePtr)), 10)), nil
+	case C.SQL_C_DOUBLE:
+		return floatNumber(float64(*(*C.SQLDOUBLE)(valuePtr))), nil
+	case C.SQL_C_BIT:
+		return *(*C.SQLCHAR)(valuePtr) != 0, nil
+	case C.SQL_C_TYPE_DATE, C.SQL_C_DATE:
+		date := (*C.SQL_DATE_STRUCT)(valuePtr)
+		return newDate(time.Date(int(date.year), time.Month(date.month), int(date.day), 0, 0, 0, 0, time.UTC)), nil
+	case C.SQL_C_TYPE_TIMESTAMP, C.SQL_C_TIMESTAMP:
+		timestamp := (*C.SQL_TIMESTAMP_STRUCT)(valuePtr)
+		return newTimestamp(time.Date(int(timestamp.year), time.Month(timestamp.month), int(timestamp.day),
+			int(timestamp.hour), int(timestamp.minute), int(timestamp.second), int(timestamp.fraction), time.UTC)), nil
+	}
+
+	return nil, &DriverError{SqlState: "HYC00", Message: fmt.Sprintf("Unsupported ValueType: %s", targetTypeToString(p.ValueType))}
 }
 
 type desc struct {
@@ -771,15 +1346,6 @@ type desc struct {
 	nullable      int
 }
 
-type cond struct {
-	column string
-	value  string
-}
-
-type stmt struct {
-	table     string
-	condition *cond
-}
 type statementHandle struct {
 	errorInfo
 	logging
@@ -792,30 +1358,87 @@ type statementHandle struct {
 	data           [][]any
 	index          int
 	rowsFetchedPtr *C.SQLULEN
-	statement      *stmt
+	statement      *selectStmt
+
+	// Block cursors, see SQL_ATTR_ROW_ARRAY_SIZE. index is the first row of
+	// the current rowset and rowsetSize the number of rows it was fetched
+	// with. Static cursors can move it anywhere in data, with -1 being before
+	// the first row and len(data) or more after the last.
+	cursorType       C.SQLULEN
+	rowArraySize     C.SQLULEN
+	rowsetSize       C.SQLULEN
+	rowBindType      C.SQLULEN
+	rowStatusPtr     *C.SQLUSMALLINT
+	rowBindOffsetPtr *C.SQLULEN
+
+	// How much of each column of the current row SQLGetData has returned,
+	// -1 once all of it has been
+	getDataOffsets map[C.SQLUSMALLINT]int
+
+	// Parameter arrays, see SQL_ATTR_PARAMSET_SIZE
+	paramsetSize       C.SQLULEN
+	paramBindType      C.SQLULEN
+	paramsProcessedPtr *C.SQLULEN
+	paramStatusPtr     *C.SQLUSMALLINT
 }
 
 func (s *statementHandle) init(connHandle *connectionHandle) {
 	s.conn = connHandle
 	s.index = -1
+	s.paramsetSize = 1
+	s.rowArraySize = 1
 	s.log = connHandle.log.With().Hex("handle_stmt", addressBytes(unsafe.Pointer(s))).Logger()
 }
 
-func (s *statementHandle) populateBinds() {
-	for idx, bind := range s.binds {
-		if bind == nil {
-			continue
-		}
+// The size of one value in a column-wise bound column array
+func (b *bind) elementSize() int {
+	return elementSize(b.TargetType, b.BufferLength)
+}
 
-		value := s.data[s.index][idx]
-		populateData(value, bind.TargetType, bind.TargetValuePtr, bind.BufferLength, bind.StrLen_or_IndPtr)
+func offsetPointer(ptr unsafe.Pointer, offset int) unsafe.Pointer {
+	if ptr == nil {
+		return nil
 	}
+	return unsafe.Add(ptr, offset)
 }
 
-func copyGoStringToCString(dst *C.uchar, src string, length int) {
-	cSrc := C.CString(src + "\x00")
-	defer C.free(unsafe.Pointer(cSrc))
-	C.strncpy((*C.char)(unsafe.Pointer(dst)), cSrc, C.size_t(length)+1) // + 1 because we add "\0"
+// Fills the bound buffers with the rows of the current rowset. With column-wise
+// binding the buffers are arrays with an element per row, with row-wise binding
+// they are in an array of structures of SQL_ATTR_ROW_BIND_TYPE bytes. Either
+// way SQL_ATTR_ROW_BIND_OFFSET_PTR is added to every address. Gives for every
+// row the warnings and errors converting its values.
+func (s *statementHandle) populateBinds(count int) [][]*DriverError {
+	diagnostics := make([][]*DriverError, count)
+	var bindOffset int
+	if s.rowBindOffsetPtr != nil {
+		bindOffset = int(*s.rowBindOffsetPtr)
+	}
+
+	for row := 0; row < count; row++ {
+		for idx, bind := range s.binds {
+			if bind == nil {
+				continue
+			}
+
+			valueOffset, indOffset := row*int(s.rowBindType), row*int(s.rowBindType)
+			if s.rowBindType == C.SQL_BIND_BY_COLUMN {
+				valueOffset, indOffset = row*bind.elementSize(), row*int(unsafe.Sizeof(C.SQLLEN(0)))
+			}
+			valuePtr := offsetPointer(unsafe.Pointer(bind.TargetValuePtr), bindOffset+valueOffset)
+			indPtr := (*C.SQLLEN)(offsetPointer(unsafe.Pointer(bind.StrLen_or_IndPtr), bindOffset+indOffset))
+
+			targetType := bind.TargetType
+			if targetType == C.SQL_C_DEFAULT {
+				targetType = defaultCType(s.def[idx].dataType)
+			}
+			value := s.data[s.index+row][idx]
+			_, err := populateData(value, targetType, C.SQLPOINTER(valuePtr), bind.BufferLength, indPtr, 0)
+			if err != nil {
+				diagnostics[row] = append(diagnostics[row], err.at(row+1, idx+1))
+			}
+		}
+	}
+	return diagnostics
 }
 
 func copyStringToBuffer(dst *C.uchar, src string, bufferSize int) int {
@@ -841,7 +1464,7 @@ func SQLGetDiagRec(
 	BufferLength C.SQLSMALLINT,
 	TextLengthPtr *C.SQLSMALLINT,
 ) C.SQLRETURN {
-	var errorInfo *DriverError
+	var diagnostics *errorInfo
 	var log zerolog.Logger
 
 	genericHandle := resolveHandle(Handle)
@@ -851,19 +1474,19 @@ func SQLGetDiagRec(
 		if HandleType != C.SQL_HANDLE_ENV {
 			return C.SQL_INVALID_HANDLE
 		}
-		errorInfo = handle.errorInfo.errorInfo
+		diagnostics = &handle.errorInfo
 		log = zerolog.Logger{}
 	case *connectionHandle:
 		if HandleType != C.SQL_HANDLE_DBC {
 			return C.SQL_INVALID_HANDLE
 		}
-		errorInfo = handle.errorInfo.errorInfo
+		diagnostics = &handle.errorInfo
 		log = handle.log.With().Str("fn", "SQLGetDiagRec").Str("handle_type", "SQL_HANDLE_DBC").Hex("handle", addressBytes(unsafe.Pointer(handle))).Logger()
 	case *statementHandle:
 		if HandleType != C.SQL_HANDLE_STMT {
 			return C.SQL_INVALID_HANDLE
 		}
-		errorInfo = handle.errorInfo.errorInfo
+		diagnostics = &handle.errorInfo
 		log = handle.log.With().Str("fn", "SQLGetDiagRec").Str("handle_type", "SQL_HANDLE_STMT").Hex("handle", addressBytes(unsafe.Pointer(handle))).Logger()
 
 	default:
@@ -886,8 +1509,8 @@ func SQLGetDiagRec(
 		*TextLengthPtr = 0
 	}
 
-	if errorInfo == nil {
-		log.Debug().Msg("errInfo was nil")
+	if len(diagnostics.diagnostics) == 0 {
+		log.Debug().Msg("no diagnostic records")
 		return C.SQL_NO_DATA
 	}
 
@@ -896,24 +1519,30 @@ func SQLGetDiagRec(
 		return C.SQL_ERROR
 	}
 
-	if RecNumber != 1 {
-		log.Debug().Msgf("RecNumber:%v != 1", RecNumber)
+	diagnostic := diagnostics.diagnostic(RecNumber)
+	if diagnostic == nil {
+		log.Debug().Msgf("RecNumber:%v > %v", RecNumber, len(diagnostics.diagnostics))
 		return C.SQL_NO_DATA
 	}
 
-	var message string
-	if errorInfo.Err != nil {
-		message = fmt.Sprintf("%s: %s", errorInfo.Message, errorInfo.Err)
-	} else {
-		message = errorInfo.Message
-	}
+	message := diagnostic.messageText()
 
-	copyStringToBuffer(SQLState, errorInfo.SqlState, 6) // 5 + \x00
-	if MessageText != nil {
+	if SQLState != nil {
+		copyStringToBuffer(SQLState, diagnostic.SqlState, 6) // 5 + \x00
+	}
+	if NativeErrorPtr != nil {
+		*NativeErrorPtr = C.SQLINTEGER(diagnostic.NativeError)
+	}
+	if MessageText != nil && BufferLength > 0 {
 		copyStringToBuffer(MessageText, message, int(BufferLength))
 	}
-	*TextLengthPtr = C.short(len(message))
+	if TextLengthPtr != nil {
+		*TextLengthPtr = C.short(len(message))
+	}
 
+	if len(message) >= int(BufferLength) && MessageText != nil {
+		return C.SQL_SUCCESS_WITH_INFO
+	}
 	return C.SQL_SUCCESS
 }
 
@@ -927,8 +1556,10 @@ func SQLGetDiagField(
 	BufferLength C.SQLSMALLINT,
 	StringLengthPtr *C.SQLSMALLINT,
 ) C.SQLRETURN {
-	var errorInfo *DriverError
+	var diagnostics *errorInfo
 	var log zerolog.Logger
+	// Where the diagnostics are from, environments having none
+	var conn *connectionHandle
 
 	genericHandle := resolveHandle(Handle)
 
@@ -937,37 +1568,54 @@ func SQLGetDiagField(
 		if HandleType != C.SQL_HANDLE_ENV {
 			return C.SQL_INVALID_HANDLE
 		}
-		errorInfo = handle.errorInfo.errorInfo
+		diagnostics = &handle.errorInfo
 		log = zerolog.Logger{}
 	case *connectionHandle:
 		if HandleType != C.SQL_HANDLE_DBC {
 			return C.SQL_INVALID_HANDLE
 		}
-		errorInfo = handle.errorInfo.errorInfo
+		diagnostics = &handle.errorInfo
+		conn = handle
 		log = handle.log.With().Str("fn", "SQLGetDiagField").Str("handle_type", "SQL_HANDLE_DBC").Hex("handle", addressBytes(unsafe.Pointer(handle))).Logger()
 	case *statementHandle:
 		if HandleType != C.SQL_HANDLE_STMT {
 			return C.SQL_INVALID_HANDLE
 		}
-		errorInfo = handle.errorInfo.errorInfo
+		diagnostics = &handle.errorInfo
+		conn = handle.conn
 		log = handle.log.With().Str("fn", "SQLGetDiagField").Str("handle_type", "SQL_HANDLE_STMT").Hex("handle", addressBytes(unsafe.Pointer(handle))).Logger()
 
 	default:
 		return C.SQL_INVALID_HANDLE
 	}
 
-	if errorInfo == nil {
-		log.Debug().Msg("errInfo was nil")
-		return C.SQL_NO_DATA
-	}
-
-	if RecNumber < 1 {
+	// The header fields describe the diagnostic area as a whole, so
+	// RecNumber is ignored
+	switch DiagIdentifier {
+	case C.SQL_DIAG_NUMBER:
+		count := len(diagnostics.diagnostics)
+		log.Debug().Str("DiagIdentifier", "SQL_DIAG_NUMBER").Int("DiagInfoPtr", count).Str("return", "SQL_SUCCESS").Send()
+		*((*C.SQLINTEGER)(DiagInfoPtr)) = C.SQLINTEGER(count)
+		return C.SQL_SUCCESS
+	case C.SQL_DIAG_RETURNCODE:
+		log.Debug().Str("DiagIdentifier", "SQL_DIAG_RETURNCODE").Int("DiagInfoPtr", int(diagnostics.returnCode)).Str("return", "SQL_SUCCESS").Send()
+		*((*C.SQLRETURN)(DiagInfoPtr)) = diagnostics.returnCode
+		return C.SQL_SUCCESS
+	}
+
+	if len(diagnostics.diagnostics) == 0 {
+		log.Debug().Msg("no diagnostic records")
+		return C.SQL_NO_DATA
+	}
+
+	if RecNumber < 1 {
 		log.Debug().Msgf("RecNumber:%v < 1", RecNumber)
 		return C.SQL_ERROR
 	}
 
-	if RecNumber != 1 {
-		log.Debug().Msgf("RecNumber:%v != 1", RecNumber)
+	diagnostic := diagnostics.diagnostic(RecNumber)
+	if diagnostic == nil {
+		log.Debug().Msgf("RecNumber:%v > %v", RecNumber, len(diagnostics.diagnostics))
 		return C.SQL_NO_DATA
 	}
 
@@ -981,29 +1629,20 @@ func SQLGetDiagField(
 	}
 
 	switch DiagIdentifier {
-	case C.SQL_DIAG_NUMBER:
-		log.Debug().Str("DiagIdentifier", "SQL_DIAG_NUMBER").Int("DiagInfoPtr", 1).Str("return", "SQL_SUCCESS").Send()
-		*((*C.SQLINTEGER)(DiagInfoPtr)) = 1
-		return C.SQL_SUCCESS
 	case C.SQL_DIAG_NATIVE:
-		log.Debug().Str("DiagIdentifier", "SQL_DIAG_NATIVE").Int("DiagInfoPtr", 0).Str("return", "SQL_SUCCESS").Send()
-		*((*C.SQLINTEGER)(DiagInfoPtr)) = 0
+		log.Debug().Str("DiagIdentifier", "SQL_DIAG_NATIVE").Int("DiagInfoPtr", diagnostic.NativeError).Str("return", "SQL_SUCCESS").Send()
+		*((*C.SQLINTEGER)(DiagInfoPtr)) = C.SQLINTEGER(diagnostic.NativeError)
 		return C.SQL_SUCCESS
 	case C.SQL_DIAG_SQLSTATE:
-		log.Debug().Str("DiagIdentifier", "SQL_DIAG_SQLSTATE").Str("DiagInfoPtr", errorInfo.SqlState).Str("return", "SQL_SUCCESS").Send()
-		return copyString(errorInfo.SqlState)
+		log.Debug().Str("DiagIdentifier", "SQL_DIAG_SQLSTATE").Str("DiagInfoPtr", diagnostic.SqlState).Str("return", "SQL_SUCCESS").Send()
+		return copyString(diagnostic.SqlState)
 	case C.SQL_DIAG_MESSAGE_TEXT:
-		var message string
-		if errorInfo.Err != nil {
-			message = fmt.Sprintf("%s: %s", errorInfo.Message, errorInfo.Err)
-		} else {
-			message = errorInfo.Message
-		}
+		message := diagnostic.messageText()
 		log.Debug().Str("DiagIdentifier", "SQL_DIAG_MESSAGE_TEXT").Str("DiagInfoPtr", message).Str("return", "SQL_SUCCESS").Send()
 		return copyString(message)
 	case C.SQL_DIAG_CLASS_ORIGIN:
 		str := "ISO 9075"
-		if strings.HasPrefix(errorInfo.SqlState, "IM") {
+		if strings.HasPrefix(diagnostic.SqlState, "IM") {
 			str = "ODBC 3.0"
 		}
 		log.Debug().Str("DiagIdentifier", "SQL_DIAG_CLASS_ORIGIN").Str("DiagInfoPtr", str).Str("return", "SQL_SUCCESS").Send()
@@ -1012,19 +1651,46 @@ func SQLGetDiagField(
 		// Logic taken from the SQLite ODBC driver, this looks different from the docs:
 		//  https://learn.microsoft.com/en-us/sql/odbc/reference/syntax/sqlgetdiagfield-function?view=sql-server-ver16
 		str := "ISO 9075"
-		if strings.HasPrefix(errorInfo.SqlState, "IM") || strings.HasPrefix(errorInfo.SqlState, "HY") || errorInfo.SqlState == "2" || errorInfo.SqlState == "0" || errorInfo.SqlState == "4" {
+		if strings.HasPrefix(diagnostic.SqlState, "IM") || strings.HasPrefix(diagnostic.SqlState, "HY") || diagnostic.SqlState == "2" || diagnostic.SqlState == "0" || diagnostic.SqlState == "4" {
 			str = "ODBC 3.0"
 		}
 		log.Debug().Str("DiagIdentifier", "SQL_DIAG_SUBCLASS_ORIGIN").Str("DiagInfoPtr", str).Str("return", "SQL_SUCCESS").Send()
 		return copyString(str)
 	case C.SQL_DIAG_CONNECTION_NAME:
-		str := "kom2" // FIXME: use dsn?
+		var str string
+		if conn != nil {
+			str = conn.dsn
+		}
 		log.Debug().Str("DiagIdentifier", "SQL_DIAG_CONNECTION_NAME").Str("DiagInfoPtr", str).Str("return", "SQL_SUCCESS").Send()
 		return copyString(str)
 	case C.SQL_DIAG_SERVER_NAME:
-		str := "inventree" // FIXME: actual configured server
+		var str string
+		if conn != nil {
+			str = conn.inventreeConfig.server
+		}
 		log.Debug().Str("DiagIdentifier", "SQL_DIAG_SERVER_NAME").Str("DiagInfoPtr", str).Str("return", "SQL_SUCCESS").Send()
 		return copyString(str)
+	case C.SQL_DIAG_ROW_NUMBER, C.SQL_DIAG_COLUMN_NUMBER:
+		// Rows and columns are only those of statements
+		if HandleType != C.SQL_HANDLE_STMT {
+			break
+		}
+		if DiagIdentifier == C.SQL_DIAG_ROW_NUMBER {
+			row := C.SQLLEN(diagnostic.RowNumber)
+			if row == 0 {
+				row = C.SQL_NO_ROW_NUMBER
+			}
+			log.Debug().Str("DiagIdentifier", "SQL_DIAG_ROW_NUMBER").Int("DiagInfoPtr", int(row)).Str("return", "SQL_SUCCESS").Send()
+			*((*C.SQLLEN)(DiagInfoPtr)) = row
+			return C.SQL_SUCCESS
+		}
+		column := C.SQLINTEGER(diagnostic.ColumnNumber)
+		if column == 0 {
+			column = C.SQL_NO_COLUMN_NUMBER
+		}
+		log.Debug().Str("DiagIdentifier", "SQL_DIAG_COLUMN_NUMBER").Int("DiagInfoPtr", int(column)).Str("return", "SQL_SUCCESS").Send()
+		*((*C.SQLINTEGER)(DiagInfoPtr)) = column
+		return C.SQL_SUCCESS
 	}
 
 	log.Error().Int("DiagIdentifier", int(DiagIdentifier)).Str("return", "SQL_ERROR").Send()
@@ -1060,6 +1726,7 @@ func SQLAllocHandle(HandleType C.SQLSMALLINT, InputHandle C.SQLHANDLE, OutputHan
 	case C.SQL_HANDLE_DBC:
 		defer setOutputHandleOnError(C.SQLHANDLE(uintptr(C.SQL_NULL_HDBC)))
 		envHandle := cgo.Handle(InputHandle).Value().(*environmentHandle)
+		envHandle.clearDiagnostics()
 		defer setErrorInfoOnError(envHandle, "Error initialising connection handle")
 		connHandle := connectionHandle{}
 		connHandle.init(envHandle)
@@ -1069,6 +1736,7 @@ func SQLAllocHandle(HandleType C.SQLSMALLINT, InputHandle C.SQLHANDLE, OutputHan
 	case C.SQL_HANDLE_STMT:
 		defer setOutputHandleOnError(C.SQLHANDLE(uintptr(C.SQL_NULL_HSTMT)))
 		connHandle := cgo.Handle(InputHandle).Value().(*connectionHandle)
+		connHandle.clearDiagnostics()
 		defer setErrorInfoOnError(connHandle, "Error initialising statement handle")
 		stmtHandle := statementHandle{}
 		stmtHandle.init(connHandle)
@@ -1084,33 +1752,43 @@ func SQLAllocHandle(HandleType C.SQLSMALLINT, InputHandle C.SQLHANDLE, OutputHan
 	return C.SQL_SUCCESS
 }
 
-func maybeUnquote(literal string) string {
-	literal = strings.TrimSpace(literal)
-
-	if len(literal) < 2 {
-		return literal
+//export SQLPrepare
+func SQLPrepare(StatementHandle C.SQLHSTMT, StatementText *C.SQLCHAR, TextLength C.SQLINTEGER) C.SQLRETURN {
+	s := resolveStatementHandle(StatementHandle)
+	if s == nil {
+		return C.SQL_INVALID_HANDLE
 	}
 
-	if literal[0] == '\'' && literal[len(literal)-1] == '\'' {
-		return strings.ReplaceAll(literal[1:len(literal)-1], "''", "'")
-	}
+	statementText := toGoString(StatementText, TextLength)
+
+	log := s.log.With().Str("fn", "SQLPrepare").Dict("args", zerolog.Dict().Str("StatementText", statementText)).Logger()
 
-	if literal[0] == '"' && literal[len(literal)-1] == '"' {
-		return strings.ReplaceAll(literal[1:len(literal)-1], "\"\"", "\"\"")
+	if err := s.prepare(statementText); err != nil {
+		return SetAndReturnError(s, err)
 	}
 
-	return literal
+	log.Info().Str("return", "SQL_SUCCESS").Send()
+	return C.SQL_SUCCESS
 }
 
-func splitSQL(sql string) []string {
-	// XXX Should support some form of escaping
-	r := regexp.MustCompile(`[^\s,"']+|"([^"]*)"|'([^']*)`)
-
-	return r.FindAllString(sql, -1)
+func (s *statementHandle) prepare(statementText string) *DriverError {
+	statement, err := parseSQL(statementText)
+	if err != nil {
+		// Rather than leaving the previous statement to be executed
+		s.statement = nil
+		return &DriverError{SqlState: "42000", Message: err.Error()}
+	}
+	s.statement = statement
+	// Fetching the schemas up front leaves executing the statement to just
+	// fetch the parts
+	for _, table := range statement.tables() {
+		s.conn.schema(table.name)
+	}
+	return nil
 }
 
-//export SQLPrepare
-func SQLPrepare(StatementHandle C.SQLHSTMT, StatementText *C.SQLCHAR, TextLength C.SQLINTEGER) (result C.SQLRETURN) {
+//export SQLExecDirect
+func SQLExecDirect(StatementHandle C.SQLHSTMT, StatementText *C.SQLCHAR, TextLength C.SQLINTEGER) C.SQLRETURN {
 	s := resolveStatementHandle(StatementHandle)
 	if s == nil {
 		return C.SQL_INVALID_HANDLE
@@ -1118,67 +1796,52 @@ func SQLPrepare(StatementHandle C.SQLHSTMT, StatementText *C.SQLCHAR, TextLength
 
 	statementText := toGoString(StatementText, TextLength)
 
-	statementText = strings.TrimSpace(statementText)
-	statementText = strings.TrimRight(statementText, ";")
-	statementText = strings.TrimSpace(statementText)
-
-	statementParts := splitSQL(statementText)
-	part := 0
+	log := s.log.With().Str("fn", "SQLExecDirect").Dict("args", zerolog.Dict().Str("StatementText", statementText)).Logger()
 
-	match := func(str string) {
-		if strings.ToUpper(statementParts[part]) != str {
-			result = SetAndReturnError(s, &DriverError{SqlState: "42000", Message: fmt.Sprintf("%s expected, got: %s", str, statementParts[part])})
-			panic("Invalid match")
-		}
-		part += 1
+	if err := s.prepare(statementText); err != nil {
+		return SetAndReturnError(s, err)
 	}
-	value := func() string {
-		value := statementParts[part]
-		part += 1
-		return value
+	if ret := s.execute(); ret != C.SQL_SUCCESS {
+		return ret
 	}
-	defer func() {
-		if err := recover(); err != nil {
-			switch e := err.(type) {
-			case error:
-				result = SetAndReturnError(s, &DriverError{SqlState: "42000", Message: fmt.Sprintf("unable to parse query: %s", e)})
-			default:
-				if result == 0 {
-					// Something else than the match paniced
-					panic(e)
-				}
-			}
-		}
-	}()
 
-	match("SELECT")
-	for ; part < len(statementParts); part++ {
-		// TODO: Record desired columns and optimise part fetching
-		if statementParts[part] == "*" {
-			break
-		} else if strings.ToUpper(statementParts[part+1]) == "FROM" {
-			break
-		}
+	log.Info().Str("return", "SQL_SUCCESS").Send()
+	return C.SQL_SUCCESS
+}
+
+//export SQLNumParams
+func SQLNumParams(StatementHandle C.SQLHSTMT, ParameterCountPtr *C.SQLSMALLINT) C.SQLRETURN {
+	s := resolveStatementHandle(StatementHandle)
+	if s == nil {
+		return C.SQL_INVALID_HANDLE
 	}
-	part += 1
-	match("FROM")
-	s.statement = &stmt{
-		table: maybeUnquote(value()),
+
+	if s.statement == nil {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY010", Message: "No statement prepared"})
 	}
-	if len(statementParts) > part {
-		match("WHERE")
-		if statementParts[part+1] != "=" {
-			return SetAndReturnError(s, &DriverError{SqlState: "42000", Message: fmt.Sprintf("= expected, got: %s", statementParts[part])})
-		}
-		s.statement.condition = &cond{
-			column: maybeUnquote(statementParts[part]),
-			value:  maybeUnquote(statementParts[part+2]),
-		}
+
+	if ParameterCountPtr != nil {
+		*ParameterCountPtr = C.SQLSMALLINT(s.statement.numParams)
 	}
 
 	return C.SQL_SUCCESS
 }
 
+func (s *statementHandle) parameterValues(set int) ([]any, *DriverError) {
+	values := make([]any, s.statement.numParams)
+	for idx := range values {
+		if idx >= len(s.params) || s.params[idx] == nil {
+			return nil, &DriverError{SqlState: "07002", Message: fmt.Sprintf("No value bound for parameter %d", idx+1), ColumnNumber: idx + 1}
+		}
+		value, err := s.params[idx].value(set, s.paramBindType)
+		if err != nil {
+			return nil, err.at(0, idx+1)
+		}
+		values[idx] = value
+	}
+	return values, nil
+}
+
 //export SQLExecute
 func SQLExecute(StatementHandle C.SQLHSTMT) C.SQLRETURN {
 	s := resolveStatementHandle(StatementHandle)
@@ -1186,35 +1849,100 @@ func SQLExecute(StatementHandle C.SQLHSTMT) C.SQLRETURN {
 		return C.SQL_INVALID_HANDLE
 	}
 
+	if s.statement == nil {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY010", Message: "No statement prepared"})
+	}
+
+	return s.execute()
+}
+
+// Records the outcome of executing the statement with a set of parameters,
+// marking the sets after a failed one as unused
+func (s *statementHandle) setParamStatus(set int, status C.SQLUSMALLINT) {
+	if s.paramsProcessedPtr != nil {
+		*s.paramsProcessedPtr = C.SQLULEN(set + 1)
+	}
+	if s.paramStatusPtr == nil {
+		return
+	}
+	statuses := unsafe.Slice(s.paramStatusPtr, s.paramsetSize)
+	statuses[set] = status
+	if status == C.SQL_PARAM_ERROR {
+		for idx := set + 1; idx < len(statuses); idx++ {
+			statuses[idx] = C.SQL_PARAM_UNUSED
+		}
+	}
+}
+
+// Executes the statement once for each set of parameters, with the result set
+// being the rows of all of them. The rows are fetched only once, for all the
+// sets together.
+func (s *statementHandle) execute() C.SQLRETURN {
 	s.index = -1
+	if s.paramsProcessedPtr != nil {
+		*s.paramsProcessedPtr = 0
+	}
 
-	if s.statement.condition == nil {
-		var parts []map[string]any
-		if err := s.fetchAllParts(s.statement.table, &parts); err != nil {
-			return SetAndReturnError(s, &DriverError{SqlState: "HY000", Message: "Unable to fetch parts", Err: err})
+	// With arrays of parameters, errors are about the set of parameters
+	// they happened with
+	failed := func(set int, err *DriverError) C.SQLRETURN {
+		s.setParamStatus(set, C.SQL_PARAM_ERROR)
+		if s.paramsetSize > 1 {
+			err = err.at(set+1, err.ColumnNumber)
 		}
-		s.populateColDesc(&parts)
-		s.data = make([][]any, 0, len(parts))
-		s.populateData(&parts)
-		if err := s.conn.updateIpnToPkMap(&parts); err != nil {
-			return SetAndReturnError(s, &DriverError{SqlState: "HY000", Message: "Unable to fetch parts", Err: err})
+		return SetAndReturnError(s, err)
+	}
+
+	paramSets := make([][]any, s.paramsetSize)
+	for set := range paramSets {
+		params, err := s.parameterValues(set)
+		if err != nil {
+			return failed(set, err)
 		}
-	} else {
-		var parts []map[string]any
-		var value any
-		if s.params == nil {
-			value = s.statement.condition.value
-		} else {
-			value = C.GoString((*C.char)(s.params[0].ParameterValuePtr))
+		paramSets[set] = params
+	}
+
+	ctx := newEvalContext(s.statement, paramSets[0])
+	if len(paramSets) > 1 {
+		ctx.paramSets = paramSets
+	}
+	for _, table := range s.statement.tables() {
+		ctx.schemas = append(ctx.schemas, s.conn.schema(table.name))
+	}
+
+	tables, err := s.fetchTables(ctx)
+	if err != nil {
+		return SetAndReturnError(s, requestError("HY000", "Unable to fetch parts", err, false))
+	}
+	ctx.setColumns(tables...)
+
+	var names []string
+	var data [][]any
+	for set, params := range paramSets {
+		ctx.params = params
+		setNames, setData, err := ctx.evaluate(tables)
+		if err != nil {
+			var driverErr *DriverError
+			if !errors.As(err, &driverErr) {
+				driverErr = &DriverError{SqlState: "HY000", Message: "Unable to evaluate the statement", Err: err}
+			}
+			return failed(set, driverErr)
 		}
-		if err := s.fetchPart(s.statement.table, s.statement.condition.column, value, &parts); err != nil {
-			return SetAndReturnError(s, &DriverError{SqlState: "HY000", Message: "Unable to fetch parts", Err: err})
+		// The rows of all the sets are fetched together, so the warnings
+		// from fetching them are about each of the sets
+		if len(s.diagnostics) > 0 {
+			s.setParamStatus(set, C.SQL_PARAM_SUCCESS_WITH_INFO)
+		} else {
+			s.setParamStatus(set, C.SQL_PARAM_SUCCESS)
 		}
-		s.populateColDesc(&parts)
-		s.data = make([][]any, 0, len(parts))
-		s.populateData(&parts)
+		names = setNames
+		data = append(data, setData...)
 	}
+	s.populateResult(names, ctx.schemaColumns(names), s.statement.typeHints(), data)
 
+	if len(s.diagnostics) > 0 {
+		return ReturnDiagnostics(s, C.SQL_SUCCESS_WITH_INFO)
+	}
 	return C.SQL_SUCCESS
 }
 
@@ -1309,6 +2037,78 @@ func rowFromMap(def []*desc, data map[string]any) []any {
 	return row
 }
 
+// Matches the search pattern arguments of catalog functions, which use the
+// LIKE wildcards with \ as their escape character. NULL matches everything.
+func searchPattern(pattern *C.SQLCHAR, length C.SQLSMALLINT) func(string) bool {
+	if pattern == nil {
+		return func(string) bool { return true }
+	}
+	segments, ok := likeSegments(toGoString(pattern, length), '\\')
+	return func(value string) bool { return ok && matchLike(segments, []rune(value)) }
+}
+
+// The number of parts the columns of a category are sampled from, for those
+// which its schema doesn't describe
+const columnSampleSize = 20
+
+// Describes the columns SELECT * gives for a category without listing all of
+// its parts. They are those of its schema, along with those of a sample of its
+// parts which the schema can't describe, being all of them for a category
+// without a schema and the metadata ones when metadata is fetched. Should the
+// sample fail to be fetched its columns are left out, with a warning.
+func (s *statementHandle) tableColumns(table string) []*desc {
+	schema := s.conn.schema(table)
+	withMetadata := s.conn.inventreeConfig.fetchMetadata
+	var columns []*desc
+	if schema != nil {
+		columns = append(columns, schema.columns...)
+		if !withMetadata {
+			return columns
+		}
+	}
+
+	var parts []map[string]any
+	err := s.listParts(table, map[string]string{"limit": strconv.Itoa(columnSampleSize)}, &parts)
+	if err == nil {
+		withParameters := schema == nil && s.conn.inventreeConfig.fetchParameters
+		err = s.fetchDetails(table, parts, false, withParameters, withMetadata)
+	}
+	if err != nil {
+		warning := &DriverError{SqlState: "01000", Message: fmt.Sprintf("Unable to sample the columns of %s", table), Err: err}
+		log := SetError(s, warning)
+		log.Warn().Err(warning).Send()
+		return columns
+	}
+	typeDates(parts, schema)
+
+	// Like for a result, a column is described by its first value that isn't
+	// NULL
+	values := make(map[string]any)
+	for _, part := range parts {
+		for key, value := range part {
+			if schema.column(key) != nil {
+				continue
+			}
+			if known, ok := values[key]; !ok || known == nil {
+				values[key] = value
+			}
+		}
+	}
+	for name, value := range values {
+		switch {
+		case name == "pk":
+			columns = append(columns, s.conn.primaryKey(table))
+		case value == nil:
+			columns = append(columns, describeColumn(name, ""))
+		default:
+			columns = append(columns, describeColumn(name, value))
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].name < columns[j].name })
+
+	return columns
+}
+
 //export SQLColumns
 func SQLColumns(StatementHandle C.SQLHSTMT, CatalogName *C.SQLCHAR, NameLength1 C.SQLSMALLINT, SchemaName *C.SQLCHAR, NameLength2 C.SQLSMALLINT, TableName *C.SQLCHAR, NameLength3 C.SQLSMALLINT, ColumnName *C.SQLCHAR, NameLength4 C.SQLSMALLINT) C.SQLRETURN {
 	s := resolveStatementHandle(StatementHandle)
@@ -1316,11 +2116,9 @@ func SQLColumns(StatementHandle C.SQLHSTMT, CatalogName *C.SQLCHAR, NameLength1
 		return C.SQL_INVALID_HANDLE
 	}
 
-	tableName := toGoString(TableName, NameLength3)
+	log := s.log.With().Str("fn", "SQLColumns").Dict("args", zerolog.Dict().Str("TableName", toGoString(TableName, NameLength3)).Str("ColumnName", toGoString(ColumnName, NameLength4))).Logger()
 
-	log := s.log.With().Str("fn", "SQLColumns").Dict("args", zerolog.Dict().Str("TableName", tableName)).Logger()
-
-	s.def = []*desc{
+	def := []*desc{
 		{name: "TABLE_CAT", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
 		{name: "TABLE_SCHEM", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
 		{name: "TABLE_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NO_NULLS},
@@ -1330,7 +2128,7 @@ func SQLColumns(StatementHandle C.SQLHSTMT, CatalogName *C.SQLCHAR, NameLength1
 		{name: "COLUMN_SIZE", dataType: C.SQL_INTEGER, nullable: C.SQL_NULLABLE},
 		{name: "BUFFER_LENGTH", dataType: C.SQL_INTEGER, nullable: C.SQL_NULLABLE},
 		{name: "DECIMAL_DIGITS", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
-		{name: "NUM_PREC_RADIX ", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "NUM_PREC_RADIX", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
 		{name: "NULLABLE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
 		{name: "REMARKS", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
 		{name: "COLUMN_DEF", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
@@ -1340,26 +2138,284 @@ func SQLColumns(StatementHandle C.SQLHSTMT, CatalogName *C.SQLCHAR, NameLength1
 		{name: "ORDINAL_POSITION", dataType: C.SQL_INTEGER, nullable: C.SQL_NO_NULLS},
 		{name: "IS_NULLABLE", dataType: C.SQL_VARCHAR, nullable: C.SQL_NO_NULLS},
 	}
+	matchTable := searchPattern(TableName, NameLength3)
+	matchColumn := searchPattern(ColumnName, NameLength4)
+
+	tables := keys(s.conn.categoryMapping)
+	sort.Strings(tables)
+	var data [][]any
+	for _, table := range tables {
+		if !matchTable(table) {
+			continue
+		}
+		for idx, column := range s.tableColumns(table) {
+			if !matchColumn(column.name) {
+				continue
+			}
+			dataType, subcode := column.verboseType()
+			row := map[string]any{
+				"TABLE_NAME":       table,
+				"COLUMN_NAME":      column.name,
+				"DATA_TYPE":        int(column.dataType),
+				"TYPE_NAME":        column.typeName(),
+				"COLUMN_SIZE":      column.colSize,
+				"BUFFER_LENGTH":    column.octetLength(),
+				"NULLABLE":         int(column.nullable),
+				"SQL_DATA_TYPE":    int(dataType),
+				"ORDINAL_POSITION": idx + 1,
+				"IS_NULLABLE":      "YES",
+			}
+			if radix := column.numPrecRadix(); radix != 0 {
+				row["NUM_PREC_RADIX"] = radix
+			}
+			if column.sqlType().hasScale() {
+				row["DECIMAL_DIGITS"] = column.decimalDigits
+			}
+			if column.dataType == C.SQL_VARCHAR {
+				row["CHAR_OCTET_LENGTH"] = column.colSize
+			}
+			if subcode != 0 {
+				row["SQL_DATETIME_SUB"] = int(subcode)
+			}
+			if column.nullable == C.SQL_NO_NULLS {
+				row["IS_NULLABLE"] = "NO"
+			}
+			data = append(data, rowFromMap(def, row))
+		}
+	}
+	s.def = def
+	s.index = -1
+	s.data = data
+
+	if len(s.diagnostics) > 0 {
+		return ReturnDiagnostics(s, C.SQL_SUCCESS_WITH_INFO)
+	}
+	log.Info().Str("return", "SQL_SUCCESS").Send()
+	return C.SQL_SUCCESS
+}
+
+//export SQLPrimaryKeys
+func SQLPrimaryKeys(StatementHandle C.SQLHSTMT, CatalogName *C.SQLCHAR, NameLength1 C.SQLSMALLINT, SchemaName *C.SQLCHAR, NameLength2 C.SQLSMALLINT, TableName *C.SQLCHAR, NameLength3 C.SQLSMALLINT) C.SQLRETURN {
+	s := resolveStatementHandle(StatementHandle)
+	if s == nil {
+		return C.SQL_INVALID_HANDLE
+	}
+
+	tableName := toGoString(TableName, NameLength3)
+
+	log := s.log.With().Str("fn", "SQLPrimaryKeys").Dict("args", zerolog.Dict().Str("TableName", tableName)).Logger()
+
+	if TableName == nil {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY009", Message: "Invalid use of null pointer: TableName"})
+	}
+
+	s.def = []*desc{
+		{name: "TABLE_CAT", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "TABLE_SCHEM", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "TABLE_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NO_NULLS},
+		{name: "COLUMN_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NO_NULLS},
+		{name: "KEY_SEQ", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
+		{name: "PK_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+	}
+	s.index = -1
+	s.data = nil
+	// Every part has a pk, which is what InvenTree identifies it by
+	if _, ok := s.conn.categoryMapping[tableName]; ok {
+		s.data = append(s.data, []any{nil, nil, tableName, "pk", 1, "pk"})
+	}
+
+	log.Info().Str("return", "SQL_SUCCESS").Send()
+	return C.SQL_SUCCESS
+}
+
+//export SQLStatistics
+func SQLStatistics(StatementHandle C.SQLHSTMT, CatalogName *C.SQLCHAR, NameLength1 C.SQLSMALLINT, SchemaName *C.SQLCHAR, NameLength2 C.SQLSMALLINT, TableName *C.SQLCHAR, NameLength3 C.SQLSMALLINT, Unique C.SQLUSMALLINT, Reserved C.SQLUSMALLINT) C.SQLRETURN {
+	s := resolveStatementHandle(StatementHandle)
+	if s == nil {
+		return C.SQL_INVALID_HANDLE
+	}
+
+	tableName := toGoString(TableName, NameLength3)
+
+	log := s.log.With().Str("fn", "SQLStatistics").Dict("args", zerolog.Dict().Str("TableName", tableName).Uint("Unique", uint(Unique)).Uint("Reserved", uint(Reserved))).Logger()
+
+	if TableName == nil {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY009", Message: "Invalid use of null pointer: TableName"})
+	}
+	if Unique != C.SQL_INDEX_UNIQUE && Unique != C.SQL_INDEX_ALL {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY100", Message: fmt.Sprintf("Uniqueness option type out of range: %d", Unique)})
+	}
+	if Reserved != C.SQL_ENSURE && Reserved != C.SQL_QUICK {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY101", Message: fmt.Sprintf("Accuracy option type out of range: %d", Reserved)})
+	}
+
+	s.def = []*desc{
+		{name: "TABLE_CAT", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "TABLE_SCHEM", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "TABLE_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NO_NULLS},
+		{name: "NON_UNIQUE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "INDEX_QUALIFIER", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "INDEX_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "TYPE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
+		{name: "ORDINAL_POSITION", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "COLUMN_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "ASC_OR_DESC", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "CARDINALITY", dataType: C.SQL_INTEGER, nullable: C.SQL_NULLABLE},
+		{name: "PAGES", dataType: C.SQL_INTEGER, nullable: C.SQL_NULLABLE},
+		{name: "FILTER_CONDITION", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+	}
+	s.index = -1
+	s.data = nil
+	// Both indexes are unique, so they are returned whatever Unique is, and
+	// sorted by name as they are of the same type
+	if _, ok := s.conn.categoryMapping[tableName]; ok {
+		for _, column := range []string{"IPN", "pk"} {
+			if column == "IPN" && !s.conn.ipnsAreUnique() {
+				continue
+			}
+			s.data = append(s.data, rowFromMap(s.def, map[string]any{
+				"TABLE_NAME":       tableName,
+				"NON_UNIQUE":       C.SQL_FALSE,
+				"INDEX_NAME":       column,
+				"TYPE":             C.SQL_INDEX_OTHER,
+				"ORDINAL_POSITION": 1,
+				"COLUMN_NAME":      column,
+			}))
+		}
+	}
+
+	log.Info().Str("return", "SQL_SUCCESS").Send()
+	return C.SQL_SUCCESS
+}
+
+//export SQLSpecialColumns
+func SQLSpecialColumns(StatementHandle C.SQLHSTMT, IdentifierType C.SQLUSMALLINT, CatalogName *C.SQLCHAR, NameLength1 C.SQLSMALLINT, SchemaName *C.SQLCHAR, NameLength2 C.SQLSMALLINT, TableName *C.SQLCHAR, NameLength3 C.SQLSMALLINT, Scope C.SQLUSMALLINT, Nullable C.SQLUSMALLINT) C.SQLRETURN {
+	s := resolveStatementHandle(StatementHandle)
+	if s == nil {
+		return C.SQL_INVALID_HANDLE
+	}
+
+	tableName := toGoString(TableName, NameLength3)
+
+	log := s.log.With().Str("fn", "SQLSpecialColumns").Dict("args", zerolog.Dict().Uint("IdentifierType", uint(IdentifierType)).Str("TableName", tableName).Uint("Scope", uint(Scope)).Uint("Nullable", uint(Nullable))).Logger()
+
+	if TableName == nil {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY009", Message: "Invalid use of null pointer: TableName"})
+	}
+	if IdentifierType != C.SQL_BEST_ROWID && IdentifierType != C.SQL_ROWVER {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY097", Message: fmt.Sprintf("Column type out of range: %d", IdentifierType)})
+	}
+	if Scope != C.SQL_SCOPE_CURROW && Scope != C.SQL_SCOPE_TRANSACTION && Scope != C.SQL_SCOPE_SESSION {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY098", Message: fmt.Sprintf("Scope type out of range: %d", Scope)})
+	}
+	if Nullable != C.SQL_NO_NULLS && Nullable != C.SQL_NULLABLE {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY099", Message: fmt.Sprintf("Nullable type out of range: %d", Nullable)})
+	}
+
+	s.def = []*desc{
+		{name: "SCOPE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "COLUMN_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NO_NULLS},
+		{name: "DATA_TYPE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
+		{name: "TYPE_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NO_NULLS},
+		{name: "COLUMN_SIZE", dataType: C.SQL_INTEGER, nullable: C.SQL_NULLABLE},
+		{name: "BUFFER_LENGTH", dataType: C.SQL_INTEGER, nullable: C.SQL_NULLABLE},
+		{name: "DECIMAL_DIGITS", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "PSEUDO_COLUMN", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+	}
+	s.index = -1
+	s.data = nil
+	// pk identifies a part for as long as it exists, which is longer than any
+	// scope, and parts have no column which changes whenever they are updated
+	if _, ok := s.conn.categoryMapping[tableName]; ok && IdentifierType == C.SQL_BEST_ROWID {
+		column := s.conn.primaryKey(tableName)
+		s.data = append(s.data, []any{
+			C.SQL_SCOPE_SESSION, column.name, int(column.dataType), column.typeName(),
+			column.colSize, column.octetLength(), column.decimalDigits, C.SQL_PC_NON_PSEUDO,
+		})
+	}
+
+	log.Info().Str("return", "SQL_SUCCESS").Send()
+	return C.SQL_SUCCESS
+}
+
+//export SQLGetTypeInfo
+func SQLGetTypeInfo(StatementHandle C.SQLHSTMT, DataType C.SQLSMALLINT) C.SQLRETURN {
+	s := resolveStatementHandle(StatementHandle)
+	if s == nil {
+		return C.SQL_INVALID_HANDLE
+	}
+
+	log := s.log.With().Str("fn", "SQLGetTypeInfo").Dict("args", zerolog.Dict().Int("DataType", int(DataType))).Logger()
+
+	// The ODBC 2 date and time types are the same types
+	switch DataType {
+	case C.SQL_DATE:
+		DataType = C.SQL_TYPE_DATE
+	case C.SQL_TIMESTAMP:
+		DataType = C.SQL_TYPE_TIMESTAMP
+	}
+
+	s.def = []*desc{
+		{name: "TYPE_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NO_NULLS},
+		{name: "DATA_TYPE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
+		{name: "COLUMN_SIZE", dataType: C.SQL_INTEGER, nullable: C.SQL_NULLABLE},
+		{name: "LITERAL_PREFIX", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "LITERAL_SUFFIX", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "CREATE_PARAMS", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "NULLABLE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
+		{name: "CASE_SENSITIVE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
+		{name: "SEARCHABLE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
+		{name: "UNSIGNED_ATTRIBUTE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "FIXED_PREC_SCALE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
+		{name: "AUTO_UNIQUE_VALUE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "LOCAL_TYPE_NAME", dataType: C.SQL_VARCHAR, nullable: C.SQL_NULLABLE},
+		{name: "MINIMUM_SCALE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "MAXIMUM_SCALE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "SQL_DATA_TYPE", dataType: C.SQL_SMALLINT, nullable: C.SQL_NO_NULLS},
+		{name: "SQL_DATETIME_SUB", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+		{name: "NUM_PREC_RADIX", dataType: C.SQL_INTEGER, nullable: C.SQL_NULLABLE},
+		{name: "INTERVAL_PRECISION", dataType: C.SQL_SMALLINT, nullable: C.SQL_NULLABLE},
+	}
 	s.index = -1
-	s.data = make([][]any, 0, 2)
-	s.data = append(s.data, rowFromMap(s.def, map[string]any{
-		"TABLE_NAME":    tableName,
-		"COLUMN_NAME":   "IPN",
-		"DATA_TYPE":     "SQL_VARCHAR",
-		"TYPE_NAME":     "VARCHAR",
-		"NULLABLE":      C.SQL_NO_NULLS,
-		"SQL_DATA_TYPE": C.SQL_VARCHAR,
-		"IS_NULLABLE":   "NO",
-	}))
-	s.data = append(s.data, rowFromMap(s.def, map[string]any{
-		"TABLE_NAME":    tableName,
-		"COLUMN_NAME":   "pk",
-		"DATA_TYPE":     "SQL_VARCHAR",
-		"TYPE_NAME":     "VARCHAR",
-		"NULLABLE":      C.SQL_NO_NULLS,
-		"SQL_DATA_TYPE": C.SQL_VARCHAR,
-		"IS_NULLABLE":   "NO",
-	}))
+	s.data = nil
+	for _, t := range sqlTypes {
+		if DataType != C.SQL_ALL_TYPES && C.short(DataType) != t.dataType {
+			continue
+		}
+		row := map[string]any{
+			"TYPE_NAME":        t.name,
+			"DATA_TYPE":        int(t.dataType),
+			"COLUMN_SIZE":      t.columnSize,
+			"NULLABLE":         C.SQL_NULLABLE,
+			"CASE_SENSITIVE":   C.SQL_FALSE,
+			"SEARCHABLE":       int(t.searchable),
+			"FIXED_PREC_SCALE": C.SQL_FALSE,
+			"SQL_DATA_TYPE":    int(t.verboseType),
+		}
+		if t.caseSensitive {
+			row["CASE_SENSITIVE"] = C.SQL_TRUE
+		}
+		if t.literalPrefix != "" {
+			row["LITERAL_PREFIX"] = t.literalPrefix
+			row["LITERAL_SUFFIX"] = t.literalSuffix
+		}
+		if t.createParams != "" {
+			row["CREATE_PARAMS"] = t.createParams
+		}
+		if t.isNumeric() {
+			row["UNSIGNED_ATTRIBUTE"] = C.SQL_FALSE
+			row["AUTO_UNIQUE_VALUE"] = C.SQL_FALSE
+			row["NUM_PREC_RADIX"] = t.numPrecRadix
+		}
+		if t.hasScale() {
+			row["MINIMUM_SCALE"] = 0
+			row["MAXIMUM_SCALE"] = t.maximumScale
+		}
+		if t.subcode != 0 {
+			row["SQL_DATETIME_SUB"] = int(t.subcode)
+		}
+		s.data = append(s.data, rowFromMap(s.def, row))
+	}
 
 	log.Info().Str("return", "SQL_SUCCESS").Send()
 	return C.SQL_SUCCESS
@@ -1376,11 +2432,24 @@ func SQLSetStmtAttr(StatementHandle C.SQLHSTMT, Attribute C.SQLINTEGER, ValuePtr
 
 	switch Attribute {
 	case C.SQL_ATTR_ROW_ARRAY_SIZE:
-		if uintptr(ValuePtr) != 1 {
-			// XXX set error
-			log.Info().Str("return", "SQL_ERROR").Send()
-			return C.SQL_ERROR
+		if uintptr(ValuePtr) < 1 {
+			return SetAndReturnError(s, &DriverError{SqlState: "HY024", Message: "SQL_ATTR_ROW_ARRAY_SIZE must be at least 1"})
 		}
+		s.rowArraySize = C.SQLULEN(uintptr(ValuePtr))
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_ROW_BIND_TYPE:
+		s.rowBindType = C.SQLULEN(uintptr(ValuePtr))
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_ROW_STATUS_PTR:
+		s.rowStatusPtr = (*C.SQLUSMALLINT)(ValuePtr)
+		log.Debug().Msg("set rowStatusPtr")
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_ROW_BIND_OFFSET_PTR:
+		s.rowBindOffsetPtr = (*C.SQLULEN)(ValuePtr)
+		log.Debug().Msg("set rowBindOffsetPtr")
 		log.Info().Str("return", "SQL_SUCCESS").Send()
 		return C.SQL_SUCCESS
 	case C.SQL_ATTR_ROWS_FETCHED_PTR:
@@ -1389,19 +2458,49 @@ func SQLSetStmtAttr(StatementHandle C.SQLHSTMT, Attribute C.SQLINTEGER, ValuePtr
 		log.Info().Str("return", "SQL_SUCCESS").Send()
 		return C.SQL_SUCCESS
 	case C.SQL_ATTR_CURSOR_TYPE:
-		if uintptr(ValuePtr) != 1 {
-			// XXX set error
-			log.Info().Str("return", "SQL_ERROR").Send()
-			return C.SQL_ERROR
+		switch C.SQLULEN(uintptr(ValuePtr)) {
+		case C.SQL_CURSOR_FORWARD_ONLY, C.SQL_CURSOR_STATIC:
+			s.cursorType = C.SQLULEN(uintptr(ValuePtr))
+		case C.SQL_CURSOR_KEYSET_DRIVEN, C.SQL_CURSOR_DYNAMIC:
+			// Results are fetched in full when executing, so they can't
+			// reflect changes made afterwards anyway
+			s.cursorType = C.SQL_CURSOR_STATIC
+			return SetAndReturnWarning(s, &DriverError{SqlState: "01S02", Message: "Option value changed, SQL_ATTR_CURSOR_TYPE is SQL_CURSOR_STATIC"})
+		default:
+			return SetAndReturnError(s, &DriverError{SqlState: "HY024", Message: fmt.Sprintf("Invalid SQL_ATTR_CURSOR_TYPE: %d", uintptr(ValuePtr))})
+		}
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_CURSOR_SCROLLABLE:
+		switch C.SQLULEN(uintptr(ValuePtr)) {
+		case C.SQL_NONSCROLLABLE:
+			s.cursorType = C.SQL_CURSOR_FORWARD_ONLY
+		case C.SQL_SCROLLABLE:
+			s.cursorType = C.SQL_CURSOR_STATIC
+		default:
+			return SetAndReturnError(s, &DriverError{SqlState: "HY024", Message: fmt.Sprintf("Invalid SQL_ATTR_CURSOR_SCROLLABLE: %d", uintptr(ValuePtr))})
 		}
 		log.Info().Str("return", "SQL_SUCCESS").Send()
 		return C.SQL_SUCCESS
 	case C.SQL_ATTR_PARAMSET_SIZE:
-		if uintptr(ValuePtr) != 1 {
-			// XXX set error
-			log.Info().Str("return", "SQL_ERROR").Send()
-			return C.SQL_ERROR
+		if uintptr(ValuePtr) < 1 {
+			return SetAndReturnError(s, &DriverError{SqlState: "HY024", Message: "SQL_ATTR_PARAMSET_SIZE must be at least 1"})
 		}
+		s.paramsetSize = C.SQLULEN(uintptr(ValuePtr))
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_PARAM_BIND_TYPE:
+		s.paramBindType = C.SQLULEN(uintptr(ValuePtr))
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_PARAMS_PROCESSED_PTR:
+		s.paramsProcessedPtr = (*C.SQLULEN)(ValuePtr)
+		log.Debug().Msg("set paramsProcessedPtr")
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_PARAM_STATUS_PTR:
+		s.paramStatusPtr = (*C.SQLUSMALLINT)(ValuePtr)
+		log.Debug().Msg("set paramStatusPtr")
 		log.Info().Str("return", "SQL_SUCCESS").Send()
 		return C.SQL_SUCCESS
 	case C.SQL_ATTR_QUERY_TIMEOUT:
@@ -1423,17 +2522,37 @@ func SQLDescribeCol(StatementHandle C.SQLHSTMT, ColumnNumber C.SQLUSMALLINT, Col
 		return C.SQL_INVALID_HANDLE
 	}
 
-	if s.def == nil || s.def[ColumnNumber-1] == nil {
+	if s.def == nil {
 		return C.SQL_SUCCESS
 	}
+	if ColumnNumber < 1 || int(ColumnNumber) > len(s.def) {
+		return SetAndReturnError(s, &DriverError{SqlState: "07009", Message: fmt.Sprintf("Invalid descriptor index: %d", ColumnNumber)})
+	}
 
 	col := s.def[ColumnNumber-1]
-	copyGoStringToCString(ColumnName, col.name, len(col.name))
-	*NameLengthPtr = C.short(len(col.name))
-	*DataTypePtr = C.short(col.dataType)
-	*NullablePtr = C.short(col.nullable)
-	*ColumnSizePtr = C.SQLULEN(col.colSize)
+	truncated := false
+	if ColumnName != nil && BufferLength > 0 {
+		truncated = copyStringToBuffer(ColumnName, col.name, int(BufferLength))-1 < len(col.name)
+	}
+	if NameLengthPtr != nil {
+		*NameLengthPtr = C.short(len(col.name))
+	}
+	if DataTypePtr != nil {
+		*DataTypePtr = C.short(col.dataType)
+	}
+	if NullablePtr != nil {
+		*NullablePtr = C.short(col.nullable)
+	}
+	if ColumnSizePtr != nil {
+		*ColumnSizePtr = C.SQLULEN(col.size())
+	}
+	if DecimalDigitsPtr != nil {
+		*DecimalDigitsPtr = C.short(col.decimalDigits)
+	}
 
+	if truncated {
+		return SetAndReturnWarning(s, &DriverError{SqlState: "01004", Message: "String data, right truncated"})
+	}
 	return C.SQL_SUCCESS
 }
 
@@ -1472,26 +2591,17 @@ func SQLFetchScroll(StatementHandle C.SQLHSTMT, FetchOrientation C.SQLSMALLINT,
 		return C.SQL_INVALID_HANDLE
 	}
 
-	s.index = s.index + 1
-	log := s.log.With().Str("fn", "SQLFetchScroll").Int("index", s.index).Logger()
-
-	if s.data == nil || s.index >= len(s.data) {
-		log.Info().Str("return", "SQL_NO_DATA").Send()
-		return C.SQL_NO_DATA
-	}
+	log := s.log.With().Str("fn", "SQLFetchScroll").Dict("args", zerolog.Dict().Int("FetchOrientation", int(FetchOrientation)).Int("FetchOffset", int(FetchOffset))).Logger()
 
-	if s.binds != nil {
-		log.Debug().Msg("populating binds")
-		s.populateBinds()
+	if FetchOrientation != C.SQL_FETCH_NEXT && s.cursorType == C.SQL_CURSOR_FORWARD_ONLY {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY106", Message: "Forward-only cursors only support SQL_FETCH_NEXT"})
 	}
 
-	if s.rowsFetchedPtr != nil {
-		log.Debug().Msg("setting rowsFetchedPtr")
-		*s.rowsFetchedPtr = 1
+	start, warning, err := s.scrollRowset(FetchOrientation, int(FetchOffset))
+	if err != nil {
+		return SetAndReturnError(s, err)
 	}
-
-	log.Info().Str("return", "SQL_SUCCESS").Send()
-	return C.SQL_SUCCESS
+	return s.fetchRowset(start-1, warning, log)
 }
 
 //export SQLFetch
@@ -1500,22 +2610,154 @@ func SQLFetch(StatementHandle C.SQLHSTMT) C.SQLRETURN {
 	if s == nil {
 		return C.SQL_INVALID_HANDLE
 	}
-	s.index = s.index + 1
-	log := s.log.With().Str("fn", "SQLFetch").Int("index", s.index).Logger()
 
-	if s.data == nil || s.index >= len(s.data) {
+	log := s.log.With().Str("fn", "SQLFetch").Logger()
+	return s.fetchRowset(s.nextRowset(), nil, log)
+}
+
+// The first row of the rowset following the current one
+func (s *statementHandle) nextRowset() int {
+	if s.index < 0 {
+		return 0
+	}
+	return s.index + int(s.rowsetSize)
+}
+
+// Gives the first row of the rowset a static cursor scrolls to, following the
+// rules of SQLFetchScroll. These number rows from 1, with 0 being before the
+// start of the result set. When the rowset would start before the first row
+// while still overlapping it, it starts at the first row with a 01S06
+// warning instead.
+func (s *statementHandle) scrollRowset(orientation C.SQLSMALLINT, offset int) (int, *DriverError, *DriverError) {
+	last := len(s.data)
+	size := int(s.rowArraySize)
+	current := s.index + 1
+	beforeStart, afterEnd := current <= 0, current > last
+	partial := &DriverError{SqlState: "01S06", Message: "Attempt to fetch before the result set returned the first rowset"}
+
+	absolute := func(offset int) (int, *DriverError, *DriverError) {
+		switch {
+		case offset < 0 && -offset <= last:
+			return last + offset + 1, nil, nil
+		case offset < 0 && -offset > size:
+			return 0, nil, nil
+		case offset < 0:
+			return 1, partial, nil
+		case offset > last:
+			return last + 1, nil, nil
+		}
+		return offset, nil, nil
+	}
+
+	switch orientation {
+	case C.SQL_FETCH_NEXT:
+		return s.nextRowset() + 1, nil, nil
+	case C.SQL_FETCH_PRIOR:
+		switch {
+		case beforeStart || current == 1:
+			return 0, nil, nil
+		case afterEnd:
+			return max(last-size+1, 1), nil, nil
+		case current <= size:
+			return 1, partial, nil
+		}
+		return current - size, nil, nil
+	case C.SQL_FETCH_FIRST:
+		return 1, nil, nil
+	case C.SQL_FETCH_LAST:
+		return max(last-size+1, 1), nil, nil
+	case C.SQL_FETCH_ABSOLUTE:
+		return absolute(offset)
+	case C.SQL_FETCH_RELATIVE:
+		switch {
+		case (beforeStart && offset > 0) || (afterEnd && offset < 0):
+			return absolute(offset)
+		case beforeStart:
+			return 0, nil, nil
+		case afterEnd:
+			return last + 1, nil, nil
+		case current+offset < 1 && (current == 1 || -offset > size):
+			return 0, nil, nil
+		case current+offset < 1:
+			return 1, partial, nil
+		}
+		return min(current+offset, last+1), nil, nil
+	case C.SQL_FETCH_BOOKMARK:
+		return 0, nil, &DriverError{SqlState: "HYC00", Message: "Bookmarks are not supported"}
+	}
+	return 0, nil, &DriverError{SqlState: "HY106", Message: fmt.Sprintf("Fetch type out of range: %d", orientation)}
+}
+
+// Makes the rowset starting at the given row the current one, filling the
+// bound buffers with its rows as well as the row status array. A diagnostic
+// given, such as a warning about the position of the rowset, is returned
+// unless converting the rows gives an error.
+func (s *statementHandle) fetchRowset(start int, diagnostic *DriverError, log zerolog.Logger) C.SQLRETURN {
+	s.index = start
+	s.rowsetSize = s.rowArraySize
+	log = log.With().Int("index", s.index).Logger()
+
+	count := 0
+	if s.data != nil && start >= 0 && start < len(s.data) {
+		count = min(int(s.rowsetSize), len(s.data)-start)
+	}
+
+	if s.rowsFetchedPtr != nil {
+		log.Debug().Int("count", count).Msg("setting rowsFetchedPtr")
+		*s.rowsFetchedPtr = C.SQLULEN(count)
+	}
+
+	if count == 0 {
 		log.Info().Str("return", "SQL_NO_DATA").Send()
 		return C.SQL_NO_DATA
 	}
 
+	// Retrieving the columns of the new row with SQLGetData starts over
+	s.getDataOffsets = nil
+
+	diagnostics := make([][]*DriverError, count)
 	if s.binds != nil {
-		log.Debug().Msg("populating binds")
-		s.populateBinds()
+		log.Debug().Int("count", count).Msg("populating binds")
+		diagnostics = s.populateBinds(count)
 	}
 
-	if s.rowsFetchedPtr != nil {
-		log.Debug().Msg("setting rowsFetchedPtr")
-		*s.rowsFetchedPtr = 1
+	var statuses []C.SQLUSMALLINT
+	if s.rowStatusPtr != nil {
+		statuses = unsafe.Slice(s.rowStatusPtr, s.rowsetSize)
+		for idx := count; idx < len(statuses); idx++ {
+			statuses[idx] = C.SQL_ROW_NOROW
+		}
+	}
+
+	// Rows that couldn't be converted are only an error when there are no
+	// other rows, otherwise they are marked as such in the row status array
+	if diagnostic != nil {
+		SetError(s, diagnostic)
+	}
+	errors, posted := 0, diagnostic != nil
+	for idx, rowDiagnostics := range diagnostics {
+		status := C.SQLUSMALLINT(C.SQL_ROW_SUCCESS)
+		for _, err := range rowDiagnostics {
+			SetError(s, err)
+			posted = true
+			if !err.isWarning() {
+				status = C.SQL_ROW_ERROR
+			} else if status == C.SQL_ROW_SUCCESS {
+				status = C.SQL_ROW_SUCCESS_WITH_INFO
+			}
+		}
+		if status == C.SQL_ROW_ERROR {
+			errors += 1
+		}
+		if statuses != nil {
+			statuses[idx] = status
+		}
+	}
+	if errors == count {
+		return ReturnDiagnostics(s, C.SQL_ERROR)
+	}
+	if posted {
+		return ReturnDiagnostics(s, C.SQL_SUCCESS_WITH_INFO)
 	}
 
 	log.Info().Str("return", "SQL_SUCCESS").Send()
@@ -1531,19 +2773,54 @@ func utf8stringToUTF16(str string) *[]uint16 {
 	return &result
 }
 
-func addUTF16Null(str *[]uint16) *[]uint16 {
-	result := utf16.AppendRune(*str, '\000')
-	return &result
-}
-
 func targetTypeToString(TargetType C.SQLSMALLINT) string {
 	switch TargetType {
 	case C.SQL_C_CHAR:
 		return "SQL_C_CHAR"
 	case C.SQL_C_WCHAR:
 		return "SQL_C_WCHAR"
+	case C.SQL_C_LONG:
+		return "SQL_C_LONG"
 	case C.SQL_C_SLONG:
 		return "SQL_C_SLONG"
+	case C.SQL_C_ULONG:
+		return "SQL_C_ULONG"
+	case C.SQL_C_SHORT:
+		return "SQL_C_SHORT"
+	case C.SQL_C_SSHORT:
+		return "SQL_C_SSHORT"
+	case C.SQL_C_USHORT:
+		return "SQL_C_USHORT"
+	case C.SQL_C_TINYINT:
+		return "SQL_C_TINYINT"
+	case C.SQL_C_STINYINT:
+		return "SQL_C_STINYINT"
+	case C.SQL_C_UTINYINT:
+		return "SQL_C_UTINYINT"
+	case C.SQL_C_SBIGINT:
+		return "SQL_C_SBIGINT"
+	case C.SQL_C_UBIGINT:
+		return "SQL_C_UBIGINT"
+	case C.SQL_C_FLOAT:
+		return "SQL_C_FLOAT"
+	case C.SQL_C_DOUBLE:
+		return "SQL_C_DOUBLE"
+	case C.SQL_C_BIT:
+		return "SQL_C_BIT"
+	case C.SQL_C_NUMERIC:
+		return "SQL_C_NUMERIC"
+	case C.SQL_C_BINARY:
+		return "SQL_C_BINARY"
+	case C.SQL_C_TYPE_DATE, C.SQL_C_DATE:
+		return "SQL_C_TYPE_DATE"
+	case C.SQL_C_TYPE_TIME, C.SQL_C_TIME:
+		return "SQL_C_TYPE_TIME"
+	case C.SQL_C_TYPE_TIMESTAMP, C.SQL_C_TIMESTAMP:
+		return "SQL_C_TYPE_TIMESTAMP"
+	case C.SQL_C_GUID:
+		return "SQL_C_GUID"
+	case C.SQL_C_DEFAULT:
+		return "SQL_C_DEFAULT"
 	default:
 		return fmt.Sprintf("??? (%d)", TargetType)
 	}
@@ -1556,112 +2833,11 @@ func min[T constraints.Ordered](a, b T) T {
 	return b
 }
 
-// See: https://learn.microsoft.com/en-us/sql/odbc/reference/appendixes/converting-data-from-sql-to-c-data-types?view=sql-server-ver16
-func populateData(value any, TargetType C.SQLSMALLINT,
-	TargetValuePtr C.SQLPOINTER, BufferLength C.SQLLEN, StrLen_or_IndPtr *C.SQLLEN,
-) {
-	switch value := value.(type) {
-	case json.Number:
-		switch TargetType {
-		case C.SQL_C_CHAR:
-			if TargetValuePtr != nil {
-				dst := (*C.char)(TargetValuePtr)
-				src := C.CString(value.String())
-				defer C.free(unsafe.Pointer(src))
-				C.strncpy(dst, src, C.size_t(BufferLength))
-			}
-			*StrLen_or_IndPtr = C.SQLLEN(len(value))
-		case C.SQL_C_WCHAR:
-			src := utf8stringToUTF16(value.String())
-			src = addUTF16Null(src)
-			length := min(C.SQLLEN(len(*src)*2), BufferLength)
-
-			if len(*src) > 0 && TargetValuePtr != nil {
-				C.memcpy(unsafe.Pointer((TargetValuePtr)), unsafe.Pointer(&(*src)[0]), C.size_t(length))
-			}
-
-			*StrLen_or_IndPtr = C.SQLLEN(len(value))
-		case C.SQL_DOUBLE:
-			if value, err := value.Float64(); err == nil {
-				*(*C.double)(TargetValuePtr) = C.double(value)
-			} else {
-				*StrLen_or_IndPtr = C.SQL_NULL_DATA
-			}
-		case C.SQL_C_SBIGINT:
-			if value, err := value.Int64(); err == nil {
-				*(*C.long)(TargetValuePtr) = C.long(value)
-			} else {
-				*StrLen_or_IndPtr = C.SQL_NULL_DATA
-			}
-		default:
-			*StrLen_or_IndPtr = C.SQL_NULL_DATA
-		}
-	case string:
-		switch TargetType {
-		case C.SQL_C_CHAR:
-			if TargetValuePtr != nil {
-				dst := (*C.char)(TargetValuePtr)
-				src := C.CString(value)
-				defer C.free(unsafe.Pointer(src))
-				C.strncpy(dst, src, C.size_t(BufferLength))
-			}
-			*StrLen_or_IndPtr = C.SQLLEN(len(value))
-		case C.SQL_C_WCHAR:
-			src := utf8stringToUTF16(value)
-			src = addUTF16Null(src)
-			length := min(C.SQLLEN(len(*src)*2), BufferLength)
-
-			if len(*src) > 0 && TargetValuePtr != nil {
-				C.memcpy(unsafe.Pointer((TargetValuePtr)), unsafe.Pointer(&(*src)[0]), C.size_t(length))
-			}
-
-			*StrLen_or_IndPtr = C.SQLLEN(len(value))
-		}
-	case bool:
-		switch TargetType {
-		case C.SQL_C_SLONG:
-			if value {
-				*(*C.long)(TargetValuePtr) = 1
-			} else {
-				*(*C.long)(TargetValuePtr) = 0
-			}
-			*StrLen_or_IndPtr = 4
-		case C.SQL_C_CHAR:
-			var strValue string
-			if value {
-				strValue = "1"
-			} else {
-				strValue = "0"
-			}
-			if TargetValuePtr != nil {
-				dst := (*C.char)(TargetValuePtr)
-				src := C.CString(strValue)
-				defer C.free(unsafe.Pointer(src))
-				C.strncpy(dst, src, C.size_t(BufferLength))
-			}
-			*StrLen_or_IndPtr = C.SQLLEN(len(strValue))
-		default:
-			*StrLen_or_IndPtr = C.SQL_NULL_DATA
-		}
-	case float64:
-		switch TargetType {
-		case C.SQL_C_CHAR:
-			value := fmt.Sprintf("%g", value)
-			if TargetValuePtr != nil {
-				dst := (*C.char)(TargetValuePtr)
-				src := C.CString(value)
-				defer C.free(unsafe.Pointer(src))
-				C.strncpy(dst, src, C.size_t(BufferLength))
-			}
-			*StrLen_or_IndPtr = C.SQLLEN(len(value))
-		default:
-			*StrLen_or_IndPtr = C.SQL_NULL_DATA
-		}
-	case nil:
-		*StrLen_or_IndPtr = C.SQL_NULL_DATA
-	default:
-		*StrLen_or_IndPtr = C.SQL_NULL_DATA
+func max[T constraints.Ordered](a, b T) T {
+	if a > b {
+		return a
 	}
+	return b
 }
 
 //export SQLGetData
@@ -1674,7 +2850,34 @@ func SQLGetData(StatementHandle C.SQLHSTMT, Col_or_Param_Num C.SQLUSMALLINT, Tar
 	}
 	log := s.log.With().Str("fn", "SQLGetData").Dict("args", zerolog.Dict().Uint("Col_or_Param_Num", uint(Col_or_Param_Num))).Int("index", s.index).Logger()
 
-	populateData(s.data[s.index][Col_or_Param_Num-1], TargetType, TargetValuePtr, BufferLength, StrLen_or_IndPtr)
+	// A scrollable cursor can be moved before the first or after the last row
+	if s.index < 0 || s.index >= len(s.data) {
+		return SetAndReturnError(s, &DriverError{SqlState: "24000", Message: "Invalid cursor state, the cursor is not positioned on a row"})
+	}
+
+	if Col_or_Param_Num < 1 || int(Col_or_Param_Num) > len(s.def) {
+		return SetAndReturnError(s, &DriverError{SqlState: "07009", Message: fmt.Sprintf("Invalid descriptor index: %d", Col_or_Param_Num)})
+	}
+	if TargetType == C.SQL_C_DEFAULT {
+		TargetType = defaultCType(s.def[Col_or_Param_Num-1].dataType)
+	}
+
+	if s.getDataOffsets == nil {
+		s.getDataOffsets = make(map[C.SQLUSMALLINT]int)
+	}
+	offset := s.getDataOffsets[Col_or_Param_Num]
+	if offset < 0 {
+		log.Info().Str("return", "SQL_NO_DATA").Send()
+		return C.SQL_NO_DATA
+	}
+
+	next, err := populateData(s.data[s.index][Col_or_Param_Num-1], TargetType, TargetValuePtr, BufferLength, StrLen_or_IndPtr, offset)
+	s.getDataOffsets[Col_or_Param_Num] = next
+	if err != nil && err.isWarning() {
+		return SetAndReturnWarning(s, err.at(0, int(Col_or_Param_Num)))
+	} else if err != nil {
+		return SetAndReturnError(s, err.at(0, int(Col_or_Param_Num)))
+	}
 
 	log.Info().Str("return", "SQL_SUCCESS").Send()
 	return C.SQL_SUCCESS
@@ -1695,18 +2898,90 @@ func SQLColAttribute(
 		return C.SQL_INVALID_HANDLE
 	}
 
+	log := s.log.With().Str("fn", "SQLColAttribute").Dict("args", zerolog.Dict().Uint("ColumnNumber", uint(ColumnNumber)).Uint("FieldIdentifier", uint(FieldIdentifier))).Logger()
+
+	returnString := func(str string) C.SQLRETURN {
+		if StringLengthPtr != nil {
+			*StringLengthPtr = C.SQLSMALLINT(len(str))
+		}
+		if CharacterAttributePtr != nil && BufferLength > 0 {
+			copyStringToBuffer((*C.uchar)(CharacterAttributePtr), str, int(BufferLength))
+		}
+		if CharacterAttributePtr != nil && len(str) >= int(BufferLength) {
+			return SetAndReturnWarning(s, &DriverError{SqlState: "01004", Message: "String data, right truncated"})
+		}
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	}
+	returnNumber := func(number int) C.SQLRETURN {
+		if NumericAttributePtr != nil {
+			*NumericAttributePtr = C.SQLLEN(number)
+		}
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	}
+
+	if FieldIdentifier == C.SQL_DESC_COUNT || FieldIdentifier == C.SQL_COLUMN_COUNT {
+		return returnNumber(len(s.def))
+	}
+	if ColumnNumber < 1 || int(ColumnNumber) > len(s.def) {
+		return SetAndReturnError(s, &DriverError{SqlState: "07009", Message: fmt.Sprintf("Invalid descriptor index: %d", ColumnNumber)})
+	}
 	col := s.def[ColumnNumber-1]
 
 	switch FieldIdentifier {
-	case C.SQL_DESC_LABEL:
-		copyGoStringToCString((*C.uchar)(CharacterAttributePtr), col.name, len(col.name))
-		//*StringLengthPtr = C.short(len(col.name))
-	default:
-		if StringLengthPtr != nil {
-			*StringLengthPtr = 0
+	case C.SQL_DESC_LABEL, C.SQL_DESC_NAME, C.SQL_COLUMN_NAME, C.SQL_DESC_BASE_COLUMN_NAME:
+		return returnString(col.name)
+	case C.SQL_DESC_TYPE_NAME:
+		return returnString(col.typeName())
+	case C.SQL_DESC_LITERAL_PREFIX:
+		return returnString(col.sqlType().literalPrefix)
+	case C.SQL_DESC_LITERAL_SUFFIX:
+		return returnString(col.sqlType().literalSuffix)
+	case C.SQL_DESC_TABLE_NAME, C.SQL_DESC_BASE_TABLE_NAME, C.SQL_DESC_CATALOG_NAME, C.SQL_DESC_SCHEMA_NAME,
+		C.SQL_DESC_LOCAL_TYPE_NAME:
+		return returnString("")
+	case C.SQL_DESC_CONCISE_TYPE:
+		return returnNumber(int(col.dataType))
+	case C.SQL_DESC_TYPE:
+		verboseType, _ := col.verboseType()
+		return returnNumber(int(verboseType))
+	case C.SQL_DESC_DATETIME_INTERVAL_CODE:
+		_, code := col.verboseType()
+		return returnNumber(int(code))
+	case C.SQL_DESC_LENGTH, C.SQL_DESC_PRECISION, C.SQL_COLUMN_LENGTH, C.SQL_COLUMN_PRECISION:
+		return returnNumber(col.size())
+	case C.SQL_DESC_SCALE, C.SQL_COLUMN_SCALE:
+		return returnNumber(col.decimalDigits)
+	case C.SQL_DESC_OCTET_LENGTH:
+		return returnNumber(col.octetLength())
+	case C.SQL_DESC_DISPLAY_SIZE:
+		return returnNumber(col.displaySize())
+	case C.SQL_DESC_NULLABLE, C.SQL_COLUMN_NULLABLE:
+		return returnNumber(col.nullable)
+	case C.SQL_DESC_NUM_PREC_RADIX:
+		return returnNumber(col.numPrecRadix())
+	case C.SQL_DESC_UNSIGNED:
+		if col.sqlType().isNumeric() {
+			return returnNumber(C.SQL_FALSE)
+		}
+		return returnNumber(C.SQL_TRUE)
+	case C.SQL_DESC_CASE_SENSITIVE:
+		if col.sqlType().caseSensitive {
+			return returnNumber(C.SQL_TRUE)
 		}
+		return returnNumber(C.SQL_FALSE)
+	case C.SQL_DESC_FIXED_PREC_SCALE, C.SQL_DESC_AUTO_UNIQUE_VALUE:
+		return returnNumber(C.SQL_FALSE)
+	case C.SQL_DESC_SEARCHABLE:
+		return returnNumber(int(col.sqlType().searchable))
+	case C.SQL_DESC_UNNAMED:
+		return returnNumber(C.SQL_NAMED)
+	case C.SQL_DESC_UPDATABLE:
+		return returnNumber(C.SQL_ATTR_READONLY)
 	}
-	return C.SQL_SUCCESS
+
+	return SetAndReturnError(s, &DriverError{SqlState: "HY091", Message: fmt.Sprintf("Invalid descriptor field identifier: %d", FieldIdentifier)})
 }
 
 //export SQLRowCount
@@ -1727,11 +3002,24 @@ func SQLRowCount(StatementHandle C.SQLHSTMT, RowCountPtr *C.SQLLEN) C.SQLRETURN
 
 //export SQLCancel
 func SQLCancel(StatementHandle C.SQLHSTMT) C.SQLRETURN {
+	if resolveStatementHandle(StatementHandle) == nil {
+		return C.SQL_INVALID_HANDLE
+	}
+
 	return C.SQL_SUCCESS
 }
 
 //export SQLFreeStmt
 func SQLFreeStmt(StatementHandle C.SQLHSTMT, Option C.SQLUSMALLINT) C.SQLRETURN {
+	s := resolveStatementHandle(StatementHandle)
+	if s == nil {
+		return C.SQL_INVALID_HANDLE
+	}
+
+	if Option == C.SQL_RESET_PARAMS {
+		s.params = nil
+	}
+
 	return C.SQL_SUCCESS
 }
 
@@ -1774,6 +3062,39 @@ func SQLGetInfo(ConnectionHandle C.SQLHDBC, InfoType C.SQLUSMALLINT, InfoValuePt
 		returnString(fmt.Sprintf("%s %s %s", Version, Commit, BuildDate))
 	case C.SQL_TXN_CAPABLE:
 		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_TC_NONE
+	case C.SQL_LIKE_ESCAPE_CLAUSE:
+		returnString("Y")
+	case C.SQL_SEARCH_PATTERN_ESCAPE:
+		returnString("\\")
+	case C.SQL_STRING_FUNCTIONS:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_FN_STR_CONCAT | C.SQL_FN_STR_LCASE | C.SQL_FN_STR_LENGTH | C.SQL_FN_STR_LTRIM |
+			C.SQL_FN_STR_REPLACE | C.SQL_FN_STR_RTRIM | C.SQL_FN_STR_SUBSTRING | C.SQL_FN_STR_UCASE | C.SQL_FN_STR_CHAR_LENGTH
+	case C.SQL_NUMERIC_FUNCTIONS:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_FN_NUM_ROUND
+	case C.SQL_CONVERT_FUNCTIONS:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_FN_CVT_CAST | C.SQL_FN_CVT_CONVERT
+	case C.SQL_SYSTEM_FUNCTIONS:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_FN_SYS_IFNULL
+	case C.SQL_OJ_CAPABILITIES:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_OJ_LEFT | C.SQL_OJ_NOT_ORDERED | C.SQL_OJ_INNER | C.SQL_OJ_ALL_COMPARISON_OPS
+	case C.SQL_SQL92_RELATIONAL_JOIN_OPERATORS:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_SRJO_INNER_JOIN | C.SQL_SRJO_LEFT_OUTER_JOIN
+	case C.SQL_PARAM_ARRAY_SELECTS:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_PAS_NO_BATCH
+	case C.SQL_PARAM_ARRAY_ROW_COUNTS:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_PARC_NO_BATCH
+	case C.SQL_SCROLL_OPTIONS:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_SO_FORWARD_ONLY | C.SQL_SO_STATIC
+	case C.SQL_FORWARD_ONLY_CURSOR_ATTRIBUTES1:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_CA1_NEXT
+	case C.SQL_STATIC_CURSOR_ATTRIBUTES1:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_CA1_NEXT | C.SQL_CA1_ABSOLUTE | C.SQL_CA1_RELATIVE
+	case C.SQL_FORWARD_ONLY_CURSOR_ATTRIBUTES2:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_CA2_READ_ONLY_CONCURRENCY
+	case C.SQL_STATIC_CURSOR_ATTRIBUTES2:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = C.SQL_CA2_READ_ONLY_CONCURRENCY | C.SQL_CA2_CRC_EXACT
+	case C.SQL_KEYSET_CURSOR_ATTRIBUTES1, C.SQL_KEYSET_CURSOR_ATTRIBUTES2, C.SQL_DYNAMIC_CURSOR_ATTRIBUTES1, C.SQL_DYNAMIC_CURSOR_ATTRIBUTES2:
+		*((*C.SQLUINTEGER)(InfoValuePtr)) = 0
 	default:
 		log.Info().Str("return", "SQL_ERROR").Send()
 		return C.SQL_ERROR
@@ -1785,6 +3106,10 @@ func SQLGetInfo(ConnectionHandle C.SQLHDBC, InfoType C.SQLUSMALLINT, InfoValuePt
 
 //export SQLDisconnect
 func SQLDisconnect(ConnectionHandle C.SQLHDBC) C.SQLRETURN {
+	if resolveConnectionHandle(ConnectionHandle) == nil {
+		return C.SQL_INVALID_HANDLE
+	}
+
 	return C.SQL_SUCCESS
 }
 
@@ -1795,6 +3120,10 @@ func SQLSetConnectAttr(
 	ValuePtr C.SQLPOINTER,
 	StringLength C.SQLINTEGER,
 ) C.SQLRETURN {
+	if resolveConnectionHandle(ConnectionHandle) == nil {
+		return C.SQL_INVALID_HANDLE
+	}
+
 	return C.SQL_SUCCESS
 }
 
@@ -1812,13 +3141,26 @@ func SQLDescribeParam(
 		return C.SQL_INVALID_HANDLE
 	}
 
-	if ParameterNumber != 1 {
-		return SetAndReturnError(s, &DriverError{SqlState: "07009", Message: "ParameterNumber != 1"})
+	if s.statement == nil {
+		return SetAndReturnError(s, &DriverError{SqlState: "HY010", Message: "No statement prepared"})
+	}
+	if ParameterNumber < 1 || int(ParameterNumber) > s.statement.numParams {
+		return SetAndReturnError(s, &DriverError{SqlState: "07009", Message: fmt.Sprintf("Invalid parameter number: %d", ParameterNumber)})
 	}
 
-	*DataTypePtr = C.SQL_VARCHAR
-	*ParameterSizePtr = 0xfffffffc // C.SQL_NO_TOTAL = -4  FIXME: Is this correct?
-	*NullablePtr = C.SQL_NO_NULLS
+	desc := describeColumn("", s.statement.paramHints()[ParameterNumber-1])
+	if DataTypePtr != nil {
+		*DataTypePtr = C.SQLSMALLINT(desc.dataType)
+	}
+	if ParameterSizePtr != nil {
+		*ParameterSizePtr = C.SQLULEN(desc.colSize)
+	}
+	if DecimalDigitsPtr != nil {
+		*DecimalDigitsPtr = C.SQLSMALLINT(desc.decimalDigits)
+	}
+	if NullablePtr != nil {
+		*NullablePtr = C.SQL_NULLABLE
+	}
 
 	return C.SQL_SUCCESS
 }
@@ -1844,30 +3186,46 @@ func SQLBindParameter(
 	if InputOutputType != C.SQL_PARAM_INPUT {
 		return SetAndReturnError(s, &DriverError{SqlState: "HYC00", Message: "InputOutputType != C.SQL_PARAM_INPUT"})
 	}
-	if ValueType != C.SQL_C_CHAR {
-		return SetAndReturnError(s, &DriverError{SqlState: "HYC00", Message: "ValueType != C.SQL_C_CHAR"})
+	switch ValueType {
+	case C.SQL_C_CHAR, C.SQL_C_WCHAR, C.SQL_C_LONG, C.SQL_C_SLONG, C.SQL_C_SBIGINT, C.SQL_C_DOUBLE, C.SQL_C_BIT,
+		C.SQL_C_TYPE_DATE, C.SQL_C_DATE, C.SQL_C_TYPE_TIMESTAMP, C.SQL_C_TIMESTAMP:
+	default:
+		return SetAndReturnError(s, &DriverError{SqlState: "HYC00", Message: fmt.Sprintf("Unsupported ValueType: %s", targetTypeToString(ValueType))})
+	}
+	if ParameterNumber < 1 {
+		return SetAndReturnError(s, &DriverError{SqlState: "07009", Message: "ParameterNumber < 1"})
 	}
 
-	param := param{
+	// Binding a parameter again replaces the earlier binding
+	for len(s.params) < int(ParameterNumber) {
+		s.params = append(s.params, nil)
+	}
+	s.params[ParameterNumber-1] = &param{
 		ValueType:         ValueType,
+		ParameterType:     ParameterType,
 		ParameterValuePtr: ParameterValuePtr,
 		BufferLength:      BufferLength,
+		StrLen_or_IndPtr:  StrLen_or_IndPtr,
 	}
-	s.params = append(s.params, &param)
 
 	return C.SQL_SUCCESS
 }
 
 //export SQLSetEnvAttr
 func SQLSetEnvAttr(EnvironmentHandle C.SQLHENV, Attribute C.SQLINTEGER, ValuePtr C.SQLPOINTER, StringLength C.SQLINTEGER) C.SQLRETURN {
+	e := resolveEnvironmentHandle(EnvironmentHandle)
+	if e == nil {
+		return C.SQL_INVALID_HANDLE
+	}
+
 	switch Attribute {
 	case C.SQL_ATTR_ODBC_VERSION:
 		if int(uintptr(ValuePtr)) != C.SQL_OV_ODBC3 {
-			return SetAndReturnError(EnvironmentHandle, &DriverError{SqlState: "HY024", Message: "Unsupported value for ODBC version"})
+			return SetAndReturnError(e, &DriverError{SqlState: "HY024", Message: "Unsupported value for ODBC version"})
 		}
 		return C.SQL_SUCCESS
 	default:
-		return SetAndReturnError(EnvironmentHandle, &DriverError{SqlState: "HYC00", Message: "Unsupported attribute"})
+		return SetAndReturnError(e, &DriverError{SqlState: "HYC00", Message: "Unsupported attribute"})
 	}
 }
 
@@ -1901,6 +3259,54 @@ func SQLGetStmtAttr(
 		}
 		log.Info().Str("return", "SQL_SUCCESS").Send()
 		return C.SQL_SUCCESS
+	case C.SQL_ATTR_CURSOR_TYPE:
+		*((*C.SQLULEN)(ValuePtr)) = s.cursorType
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_CURSOR_SCROLLABLE:
+		if s.cursorType == C.SQL_CURSOR_FORWARD_ONLY {
+			*((*C.SQLULEN)(ValuePtr)) = C.SQL_NONSCROLLABLE
+		} else {
+			*((*C.SQLULEN)(ValuePtr)) = C.SQL_SCROLLABLE
+		}
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_ROW_ARRAY_SIZE:
+		*((*C.SQLULEN)(ValuePtr)) = s.rowArraySize
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_ROW_BIND_TYPE:
+		*((*C.SQLULEN)(ValuePtr)) = s.rowBindType
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_ROW_STATUS_PTR:
+		*((**C.SQLUSMALLINT)(ValuePtr)) = s.rowStatusPtr
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_ROW_BIND_OFFSET_PTR:
+		*((**C.SQLULEN)(ValuePtr)) = s.rowBindOffsetPtr
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_ROWS_FETCHED_PTR:
+		*((**C.SQLULEN)(ValuePtr)) = s.rowsFetchedPtr
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_PARAMSET_SIZE:
+		*((*C.SQLULEN)(ValuePtr)) = s.paramsetSize
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_PARAM_BIND_TYPE:
+		*((*C.SQLULEN)(ValuePtr)) = s.paramBindType
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_PARAMS_PROCESSED_PTR:
+		*((**C.SQLULEN)(ValuePtr)) = s.paramsProcessedPtr
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
+	case C.SQL_ATTR_PARAM_STATUS_PTR:
+		*((**C.SQLUSMALLINT)(ValuePtr)) = s.paramStatusPtr
+		log.Info().Str("return", "SQL_SUCCESS").Send()
+		return C.SQL_SUCCESS
 	}
 
 	log.Info().Str("return", "SQL_ERROR").Send()
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func containsAggregate(e expr) bool {
+	found := false
+	walkExpr(e, func(e expr) {
+		if call, ok := e.(*funcCall); ok && aggregateFunctions[call.name] {
+			found = true
+		}
+	})
+	return found
+}
+
+// Statements with GROUP BY, HAVING or aggregates in the select list or ORDER
+// BY produce one row per group rather than one row per part
+func (stmt *selectStmt) isAggregate() bool {
+	if stmt.groupBy != nil || stmt.having != nil {
+		return true
+	}
+	for _, column := range stmt.columns {
+		if containsAggregate(column.expr) {
+			return true
+		}
+	}
+	for _, term := range stmt.orderBy {
+		if containsAggregate(term.expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// For columns whose type doesn't depend on the data, gives a value of that
+// type, so that they are described the same no matter what the data is
+func (stmt *selectStmt) typeHints() []any {
+	hints := make([]any, len(stmt.columns))
+	for idx, column := range stmt.columns {
+		switch e := column.expr.(type) {
+		case *funcCall:
+			switch e.name {
+			case "COUNT":
+				hints[idx] = json.Number("0")
+			case "AVG":
+				hints[idx] = floatNumber(0)
+			default:
+				if function, ok := scalarFunctions[e.name]; ok {
+					hints[idx] = function.result
+				}
+			}
+		case *castExpr:
+			hints[idx] = e.to.hint()
+		case *binaryExpr:
+			if e.op == "||" {
+				hints[idx] = ""
+			}
+		}
+	}
+	return hints
+}
+
+// Gives a key under which equal values are equal, used for grouping and for
+// finding distinct values. Numbers are keyed by their value, so 1 and 1.0
+// are the same, while strings are text even when they hold a number, as
+// with comparing strings to strings: "0603" isn't "603".
+func valueKey(value any) string {
+	if value == nil {
+		return "N"
+	}
+	if isNumeric(value) {
+		if number, ok := toNumber(value); ok {
+			return "F" + strconv.FormatFloat(number, 'g', -1, 64)
+		}
+	}
+	return "S" + toString(value)
+}
+
+func valuesKey(values []any) string {
+	keys := make([]string, len(values))
+	for idx, value := range values {
+		keys[idx] = valueKey(value)
+	}
+	return strings.Join(keys, "\x00")
+}
+
+// Turns the rows into result rows, grouping them if the statement is an
+// aggregate and then applying HAVING to the groups. Groups are in the order
+// of their first row.
+func (c *evalContext) groupRows(rows []map[string]any) ([]*resultRow, error) {
+	if !c.stmt.isAggregate() {
+		result := make([]*resultRow, len(rows))
+		for idx, row := range rows {
+			result[idx] = &resultRow{row: row}
+		}
+		return result, nil
+	}
+
+	exprs := make([]expr, len(c.stmt.groupBy))
+	for idx, e := range c.stmt.groupBy {
+		e, err := c.selectListExpr("GROUP BY", e, rows)
+		if err != nil {
+			return nil, err
+		}
+		if containsAggregate(e) {
+			return nil, &DriverError{SqlState: "42000", Message: fmt.Sprintf("Aggregate functions are not allowed in GROUP BY at position %d", c.stmt.characterPosition(e.position()))}
+		}
+		exprs[idx] = e
+	}
+
+	var groups []*resultRow
+	if len(exprs) == 0 {
+		// Aggregating without GROUP BY gives a single row, even for no rows
+		group := &resultRow{group: append([]map[string]any{}, rows...)}
+		if len(rows) > 0 {
+			group.row = rows[0]
+		}
+		groups = append(groups, group)
+	} else {
+		index := make(map[string]*resultRow)
+		for _, row := range rows {
+			values := make([]any, len(exprs))
+			for idx, e := range exprs {
+				value, err := c.eval(e, row)
+				if err != nil {
+					return nil, err
+				}
+				values[idx] = value
+			}
+			key := valuesKey(values)
+			group, ok := index[key]
+			if !ok {
+				group = &resultRow{row: row}
+				index[key] = group
+				groups = append(groups, group)
+			}
+			group.group = append(group.group, row)
+		}
+	}
+
+	if c.stmt.having == nil {
+		return groups, nil
+	}
+
+	result := make([]*resultRow, 0, len(groups))
+	for _, group := range groups {
+		c.group = group.group
+		matched, err := c.evalCondition(c.stmt.having, group.row)
+		c.group = nil
+		if err != nil {
+			return nil, err
+		}
+		if matched == true {
+			result = append(result, group)
+		}
+	}
+	return result, nil
+}
+
+// Formats a float such that it is still described as a SQL_DOUBLE when it
+// happens to be a whole number
+func floatNumber(f float64) json.Number {
+	text := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(text, ".eEnN") {
+		text += ".0"
+	}
+	return json.Number(text)
+}
+
+func (c *evalContext) evalAggregate(e *funcCall) (any, error) {
+	if c.group == nil {
+		return nil, &DriverError{SqlState: "42000", Message: fmt.Sprintf("Aggregate function %s is not allowed here at position %d", e.name, c.stmt.characterPosition(e.pos))}
+	}
+
+	rows := c.group
+	if e.star {
+		return json.Number(strconv.Itoa(len(rows))), nil
+	}
+
+	// The argument is evaluated per row, where aggregates can't be nested
+	c.group = nil
+	defer func() { c.group = rows }()
+
+	var values []any
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		value, err := c.eval(e.args[0], row)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+		if e.distinct {
+			key := valueKey(value)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		values = append(values, value)
+	}
+
+	switch e.name {
+	case "COUNT":
+		return json.Number(strconv.Itoa(len(values))), nil
+	case "MIN", "MAX":
+		var result any
+		for _, value := range values {
+			cmp := 0
+			if result != nil {
+				cmp = compareValues(value, result)
+			}
+			if result == nil || (e.name == "MIN" && cmp < 0) || (e.name == "MAX" && cmp > 0) {
+				result = value
+			}
+		}
+		return result, nil
+	case "SUM", "AVG":
+		if len(values) == 0 {
+			return nil, nil
+		}
+		integral := e.name == "SUM"
+		var intSum int64
+		var floatSum float64
+		for _, value := range values {
+			number, ok := toNumber(value)
+			if !ok {
+				return nil, &DriverError{SqlState: "22018", Message: fmt.Sprintf("Invalid numeric value for %s at position %d: %v", e.name, c.stmt.characterPosition(e.pos), value)}
+			}
+			floatSum += number
+			if i, ok := toInteger(value); ok && integral {
+				intSum += i
+			} else {
+				integral = false
+			}
+		}
+		if integral {
+			return json.Number(strconv.FormatInt(intSum, 10)), nil
+		}
+		if e.name == "AVG" {
+			return floatNumber(floatSum / float64(len(values))), nil
+		}
+		return floatNumber(floatSum), nil
+	}
+
+	return nil, &DriverError{SqlState: "HYC00", Message: fmt.Sprintf("Unsupported function at position %d: %s", c.stmt.characterPosition(e.pos), e.name)}
+}
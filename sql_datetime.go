@@ -0,0 +1,81 @@
+package main
+
+import (
+	"time"
+)
+
+// Dates and timestamps are kept in their ODBC character representation, like
+// json.Number keeps numbers, so they convert to strings as they are
+type sqlDate string
+type sqlTimestamp string
+
+const (
+	dateFormat      = "2006-01-02"
+	timestampFormat = "2006-01-02 15:04:05.999999"
+)
+
+func newDate(t time.Time) sqlDate {
+	return sqlDate(t.Format(dateFormat))
+}
+
+// Timestamps with a time zone, as InvenTree gives them, are converted to UTC
+// as SQL_TYPE_TIMESTAMP has none
+func newTimestamp(t time.Time) sqlTimestamp {
+	return sqlTimestamp(t.UTC().Format(timestampFormat))
+}
+
+// Part fields which InvenTree knows to be dates
+var dateFields = map[string]bool{
+	"creation_date":  true,
+	"last_stocktake": true,
+}
+
+// Gives the date or timestamp a string holds
+func parseDateTime(text string) (any, bool) {
+	if t, err := time.Parse(dateFormat, text); err == nil {
+		return newDate(t), true
+	}
+	if t, ok := parseTime(text, timestampLayouts); ok {
+		return newTimestamp(t), true
+	}
+	return nil, false
+}
+
+// Replaces the strings of the parts' date and timestamp fields with sqlDate
+// and sqlTimestamp values, so that they are described as such and compare as
+// such. Other strings, such as those of metadata and parameters, stay strings
+// even when they look like dates.
+func typeDates(parts []map[string]any, schema *tableSchema) {
+	for _, part := range parts {
+		for key, value := range part {
+			text, ok := value.(string)
+			if !ok || !(dateFields[key] || schema.isDateTime(key)) {
+				continue
+			}
+			if value, ok := parseDateTime(text); ok {
+				part[key] = value
+			}
+		}
+	}
+}
+
+func isDateTime(value any) bool {
+	switch value.(type) {
+	case sqlDate, sqlTimestamp:
+		return true
+	}
+	return false
+}
+
+// Dates are taken for midnight UTC, as are timestamps without a time zone
+func toTime(value any) (time.Time, bool) {
+	switch value := value.(type) {
+	case sqlDate:
+		return parseTime(string(value), dateLayouts)
+	case sqlTimestamp:
+		return parseTime(string(value), timestampLayouts)
+	case string:
+		return parseTime(value, dateLayouts, timestampLayouts)
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,1172 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Statements are evaluated against rows in their flattened map form, i.e.
+// as returned by fetchAllParts and fetchPart, before they are turned into
+// result set columns.
+type evalContext struct {
+	stmt   *selectStmt
+	params []any
+	// Maps the column names used in the statement onto the keys of the rows
+	columns map[string]string
+	// Column names which are ambiguous between joined tables
+	ambiguous map[string]bool
+	// The schema of each table of the FROM clause, nil for those without one
+	schemas []*tableSchema
+	// The keys of every table in the order of their tables, i.e. the columns
+	// of SELECT *
+	starKeys []string
+	// For joins, the index of the table each key belongs to and the tables
+	// without any rows or schema, whose columns aren't known
+	keyTables   map[string]int
+	emptyTables map[int]bool
+	// Set when the server already did the ordering or limiting of the rows
+	orderPushedDown bool
+	limitPushedDown bool
+	// The rows of the group being evaluated, for evaluating aggregates
+	group []map[string]any
+	// Every set of parameters when executing with a parameter array, for
+	// fetching the rows for all of them at once
+	paramSets [][]any
+}
+
+func newEvalContext(stmt *selectStmt, params []any) *evalContext {
+	return &evalContext{stmt: stmt, params: params}
+}
+
+// Sets up the column names for the rows of each table of the FROM clause,
+// which are those of the rows as well as those of the table's schema.
+// Unqualified names of joined tables' columns refer to the column of whichever
+// table has it, unless more than one does.
+func (c *evalContext) setColumns(tables ...[]map[string]any) {
+	c.columns = make(map[string]string)
+	c.ambiguous = make(map[string]bool)
+	candidates := make(map[string]map[string]bool)
+	var names []string
+	addName := func(name, key string) {
+		if candidates[name] == nil {
+			candidates[name] = make(map[string]bool)
+			names = append(names, name)
+		}
+		candidates[name][key] = true
+	}
+	c.starKeys = nil
+	if len(c.stmt.joins) > 0 {
+		c.keyTables = make(map[string]int)
+		c.emptyTables = make(map[int]bool)
+	}
+	for idx, rows := range tables {
+		var schema *tableSchema
+		if idx < len(c.schemas) {
+			schema = c.schemas[idx]
+		}
+		if len(rows) == 0 && schema == nil && c.emptyTables != nil {
+			c.emptyTables[idx] = true
+		}
+		var tableKeys []string
+		addKey := func(key string) {
+			if _, ok := candidates[key]; ok {
+				return
+			}
+			addName(key, key)
+			tableKeys = append(tableKeys, key)
+			if c.keyTables != nil {
+				c.keyTables[key] = idx
+			}
+		}
+		for _, row := range rows {
+			for key := range row {
+				addKey(key)
+			}
+		}
+		if schema != nil {
+			for _, column := range schema.columns {
+				if c.keyTables != nil {
+					addKey(c.stmt.tables()[idx].qualifier() + "." + column.name)
+				} else {
+					addKey(column.name)
+				}
+			}
+		}
+		sort.Strings(tableKeys)
+		c.starKeys = append(c.starKeys, tableKeys...)
+	}
+	for key := range c.keyTables {
+		_, name := c.stmt.splitQualified(key)
+		addName(name, key)
+	}
+
+	for _, name := range names {
+		if len(candidates[name]) > 1 {
+			c.ambiguous[name] = true
+			continue
+		}
+		c.columns[name] = keys(candidates[name])[0]
+	}
+	// Unquoted identifiers are matched case insensitively, as long as that
+	// doesn't make them ambiguous
+	folded := make(map[string]map[string]bool)
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if folded[lower] == nil {
+			folded[lower] = make(map[string]bool)
+		}
+		for key := range candidates[name] {
+			folded[lower][key] = true
+		}
+	}
+	for lower, keySet := range folded {
+		if _, ok := c.columns[lower]; ok || c.ambiguous[lower] {
+			continue
+		}
+		if len(keySet) == 1 {
+			c.columns[lower] = keys(keySet)[0]
+		} else {
+			c.ambiguous[lower] = true
+		}
+	}
+}
+
+// Parameters and metadata differ from part to part, so a part without the
+// parameter or metadata in question simply has a NULL value for it
+func isDynamicColumn(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasPrefix(lower, "parameter.") || strings.HasPrefix(lower, "metadata.")
+}
+
+func (c *evalContext) resolveColumn(ref *columnRef) (string, error) {
+	if key, ok := c.columns[ref.name]; ok {
+		return key, nil
+	}
+	if c.ambiguous[ref.name] {
+		return "", c.ambiguousColumn(ref)
+	}
+	if key, ok := c.columns[strings.ToLower(ref.name)]; ok {
+		return key, nil
+	}
+	if c.ambiguous[strings.ToLower(ref.name)] {
+		return "", c.ambiguousColumn(ref)
+	}
+	if table, name := c.stmt.splitQualified(ref.name); isDynamicColumn(name) || c.emptyTables[table] {
+		return ref.name, nil
+	}
+	return "", &DriverError{SqlState: "42S22", Message: fmt.Sprintf("Column not found at position %d: %s", c.stmt.characterPosition(ref.pos), ref.name)}
+}
+
+func walkExpr(e expr, fn func(expr)) {
+	if e == nil {
+		return
+	}
+	fn(e)
+	switch e := e.(type) {
+	case *unaryExpr:
+		walkExpr(e.operand, fn)
+	case *binaryExpr:
+		walkExpr(e.left, fn)
+		walkExpr(e.right, fn)
+	case *isNullExpr:
+		walkExpr(e.operand, fn)
+	case *likeExpr:
+		walkExpr(e.operand, fn)
+		walkExpr(e.pattern, fn)
+		walkExpr(e.escape, fn)
+	case *inExpr:
+		walkExpr(e.operand, fn)
+		for _, item := range e.list {
+			walkExpr(item, fn)
+		}
+	case *betweenExpr:
+		walkExpr(e.operand, fn)
+		walkExpr(e.low, fn)
+		walkExpr(e.high, fn)
+	case *funcCall:
+		for _, arg := range e.args {
+			walkExpr(arg, fn)
+		}
+	case *castExpr:
+		walkExpr(e.operand, fn)
+	}
+}
+
+func referencedColumns(e expr) []string {
+	var columns []string
+	walkExpr(e, func(e expr) {
+		if ref, ok := e.(*columnRef); ok {
+			columns = append(columns, ref.name)
+		}
+	})
+	return columns
+}
+
+// Works out whether executing the statement requires the parameters and the
+// metadata of the parts, which each take extra requests to fetch
+func (stmt *selectStmt) needsDetails() (parameters bool, metadata bool) {
+	if stmt.columns == nil {
+		return true, true
+	}
+
+	var columns []string
+	for _, column := range stmt.columns {
+		columns = append(columns, referencedColumns(column.expr)...)
+	}
+	columns = append(columns, referencedColumns(stmt.where)...)
+	for _, e := range stmt.groupBy {
+		columns = append(columns, referencedColumns(e)...)
+	}
+	columns = append(columns, referencedColumns(stmt.having)...)
+	for _, term := range stmt.orderBy {
+		columns = append(columns, referencedColumns(term.expr)...)
+	}
+	for _, column := range columns {
+		_, column = stmt.splitQualified(column)
+		lower := strings.ToLower(column)
+		parameters = parameters || strings.HasPrefix(lower, "parameter.")
+		metadata = metadata || strings.HasPrefix(lower, "metadata.")
+	}
+	return parameters, metadata
+}
+
+// Splits an expression into the operands of its top level ANDs
+func conjuncts(e expr) []expr {
+	if binary, ok := e.(*binaryExpr); ok && binary.op == "AND" {
+		return append(conjuncts(binary.left), conjuncts(binary.right)...)
+	}
+	if e == nil {
+		return nil
+	}
+	return []expr{e}
+}
+
+func toNumber(value any) (float64, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		f, err := value.Float64()
+		return f, err == nil
+	case float64:
+		return value, true
+	case int64:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case bool:
+		if value {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func toInteger(value any) (int64, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		i, err := value.Int64()
+		return i, err == nil
+	case int64:
+		return value, true
+	case int:
+		return int64(value), true
+	case bool:
+		if value {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+func isNumeric(value any) bool {
+	switch value.(type) {
+	case json.Number, float64, int64, int, bool:
+		return true
+	}
+	return false
+}
+
+func toString(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case sqlDate:
+		return string(value)
+	case sqlTimestamp:
+		return string(value)
+	case float64:
+		return strconv.FormatFloat(value, 'G', -1, 64)
+	case bool:
+		if value {
+			return "1"
+		}
+		return "0"
+	}
+	return fmt.Sprint(value)
+}
+
+func compareNumbers[T int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Compares two non-NULL values. Numbers (including booleans, which the driver
+// reports as integers) compare numerically, also against strings that hold a
+// number, dates and timestamps compare chronologically, also against strings
+// that hold one, everything else compares as strings.
+func compareValues(a, b any) int {
+	if isDateTime(a) || isDateTime(b) {
+		if at, ok := toTime(a); ok {
+			if bt, ok := toTime(b); ok {
+				return at.Compare(bt)
+			}
+		}
+	}
+	if isNumeric(a) || isNumeric(b) {
+		if ai, ok := toInteger(a); ok {
+			if bi, ok := toInteger(b); ok {
+				return compareNumbers(ai, bi)
+			}
+		}
+		if af, ok := toNumber(a); ok {
+			if bf, ok := toNumber(b); ok {
+				return compareNumbers(af, bf)
+			}
+		}
+	}
+	return strings.Compare(toString(a), toString(b))
+}
+
+// Negates a three valued truth value
+func not3(value any, not bool) any {
+	if value == nil || !not {
+		return value
+	}
+	return !value.(bool)
+}
+
+type likeSegment struct {
+	// '%' or '_' for wildcards, otherwise 0 and literal holds the rune to match
+	wildcard rune
+	literal  rune
+}
+
+func (c *evalContext) compileLike(e *likeExpr, pattern string, escape any) ([]likeSegment, error) {
+	var escapeRune rune = -1
+	if escape != nil {
+		runes := []rune(toString(escape))
+		if len(runes) != 1 {
+			return nil, &DriverError{SqlState: "22019", Message: fmt.Sprintf("Invalid escape character at position %d: %q", c.stmt.characterPosition(e.escape.position()), toString(escape))}
+		}
+		escapeRune = runes[0]
+	}
+
+	segments, ok := likeSegments(pattern, escapeRune)
+	if !ok {
+		return nil, &DriverError{SqlState: "22025", Message: fmt.Sprintf("Invalid escape sequence at position %d: %q", c.stmt.characterPosition(e.pattern.position()), pattern)}
+	}
+
+	return segments, nil
+}
+
+// Splits a LIKE pattern into segments, false when an escape character isn't
+// followed by a wildcard or another escape character. Consecutive % are the
+// same as one, so they're collapsed.
+func likeSegments(pattern string, escapeRune rune) ([]likeSegment, bool) {
+	var segments []likeSegment
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == escapeRune:
+			i += 1
+			if i == len(runes) || (runes[i] != '%' && runes[i] != '_' && runes[i] != escapeRune) {
+				return nil, false
+			}
+			segments = append(segments, likeSegment{literal: runes[i]})
+		case r == '%' && len(segments) > 0 && segments[len(segments)-1].wildcard == '%':
+		case r == '%' || r == '_':
+			segments = append(segments, likeSegment{wildcard: r})
+		default:
+			segments = append(segments, likeSegment{literal: r})
+		}
+	}
+
+	return segments, true
+}
+
+// Matches the value iteratively, going back only to just after where the
+// last % started matching when the rest doesn't match. Any earlier % can't
+// do better, so this doesn't take exponential time with several of them.
+func matchLike(segments []likeSegment, value []rune) bool {
+	si, vi := 0, 0
+	// The segment after the last %, and the value where it was tried, -1
+	// before there has been a %
+	retrySegment, retryValue := -1, 0
+	for vi < len(value) {
+		if si < len(segments) {
+			switch segment := segments[si]; {
+			case segment.wildcard == '%':
+				si++
+				retrySegment, retryValue = si, vi
+				continue
+			case segment.wildcard == '_' || segment.literal == value[vi]:
+				si++
+				vi++
+				continue
+			}
+		}
+		if retrySegment < 0 {
+			return false
+		}
+		// Let the % match one more character
+		retryValue++
+		si, vi = retrySegment, retryValue
+	}
+	for si < len(segments) && segments[si].wildcard == '%' {
+		si++
+	}
+	return si == len(segments)
+}
+
+func (c *evalContext) evalLike(e *likeExpr, row map[string]any) (any, error) {
+	value, err := c.eval(e.operand, row)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := c.eval(e.pattern, row)
+	if err != nil {
+		return nil, err
+	}
+	var escape any
+	if e.escape != nil {
+		if escape, err = c.eval(e.escape, row); err != nil {
+			return nil, err
+		}
+	}
+	if value == nil || pattern == nil {
+		return nil, nil
+	}
+
+	segments, err := c.compileLike(e, toString(pattern), escape)
+	if err != nil {
+		return nil, err
+	}
+	return not3(matchLike(segments, []rune(toString(value))), e.not), nil
+}
+
+func (c *evalContext) evalIn(e *inExpr, row map[string]any) (any, error) {
+	value, err := c.eval(e.operand, row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	var result any = false
+	for _, item := range e.list {
+		candidate, err := c.eval(item, row)
+		if err != nil {
+			return nil, err
+		}
+		if candidate == nil {
+			result = nil
+		} else if compareValues(value, candidate) == 0 {
+			result = true
+			break
+		}
+	}
+	return not3(result, e.not), nil
+}
+
+func (c *evalContext) evalBetween(e *betweenExpr, row map[string]any) (any, error) {
+	value, err := c.eval(e.operand, row)
+	if err != nil {
+		return nil, err
+	}
+	low, err := c.eval(e.low, row)
+	if err != nil {
+		return nil, err
+	}
+	high, err := c.eval(e.high, row)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	// value >= low AND value <= high, either of which may be unknown
+	var result any = true
+	for _, bound := range []struct {
+		value any
+		sign  int
+	}{{low, 1}, {high, -1}} {
+		if bound.value == nil {
+			result = nil
+		} else if compareValues(value, bound.value)*bound.sign < 0 {
+			result = false
+			break
+		}
+	}
+	return not3(result, e.not), nil
+}
+
+// Evaluates a condition using SQL's three valued logic, nil being unknown
+func (c *evalContext) evalCondition(e expr, row map[string]any) (any, error) {
+	value, err := c.eval(e, row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	switch value := value.(type) {
+	case bool:
+		return value, nil
+	default:
+		if number, ok := toNumber(value); ok && isNumeric(value) {
+			return number != 0, nil
+		}
+	}
+	return nil, &DriverError{SqlState: "22018", Message: fmt.Sprintf("Invalid boolean value at position %d: %v", c.stmt.characterPosition(e.position()), value)}
+}
+
+func (c *evalContext) eval(e expr, row map[string]any) (any, error) {
+	switch e := e.(type) {
+	case *literal:
+		return e.value, nil
+	case *paramRef:
+		if e.index >= len(c.params) {
+			return nil, &DriverError{SqlState: "07002", Message: fmt.Sprintf("No value bound for parameter %d", e.index+1)}
+		}
+		return c.params[e.index], nil
+	case *columnRef:
+		// Only happens for the single group of an aggregate over no rows
+		if row == nil {
+			return nil, nil
+		}
+		key, err := c.resolveColumn(e)
+		if err != nil {
+			return nil, err
+		}
+		return row[key], nil
+	case *isNullExpr:
+		value, err := c.eval(e.operand, row)
+		if err != nil {
+			return nil, err
+		}
+		return (value == nil) != e.not, nil
+	case *likeExpr:
+		return c.evalLike(e, row)
+	case *inExpr:
+		return c.evalIn(e, row)
+	case *betweenExpr:
+		return c.evalBetween(e, row)
+	case *funcCall:
+		return c.evalFunction(e, row)
+	case *castExpr:
+		return c.evalCast(e, row)
+	case *unaryExpr:
+		switch e.op {
+		case "NOT":
+			value, err := c.evalCondition(e.operand, row)
+			if err != nil || value == nil {
+				return nil, err
+			}
+			return !value.(bool), nil
+		case "-", "+":
+			value, err := c.eval(e.operand, row)
+			if err != nil || value == nil {
+				return nil, err
+			}
+			if e.op == "+" {
+				return value, nil
+			}
+			if i, ok := toInteger(value); ok && isNumeric(value) {
+				return json.Number(strconv.FormatInt(-i, 10)), nil
+			}
+			if f, ok := toNumber(value); ok {
+				return json.Number(strconv.FormatFloat(-f, 'G', -1, 64)), nil
+			}
+			return nil, &DriverError{SqlState: "22018", Message: fmt.Sprintf("Invalid numeric value at position %d: %v", c.stmt.characterPosition(e.pos), value)}
+		}
+	case *binaryExpr:
+		switch e.op {
+		case "||":
+			left, err := c.eval(e.left, row)
+			if err != nil {
+				return nil, err
+			}
+			right, err := c.eval(e.right, row)
+			if err != nil || left == nil || right == nil {
+				return nil, err
+			}
+			return toString(left) + toString(right), nil
+		case "AND", "OR":
+			left, err := c.evalCondition(e.left, row)
+			if err != nil {
+				return nil, err
+			}
+			// Short circuit where the result is already known
+			if left != nil && left.(bool) == (e.op == "OR") {
+				return left, nil
+			}
+			right, err := c.evalCondition(e.right, row)
+			if err != nil {
+				return nil, err
+			}
+			if right != nil && right.(bool) == (e.op == "OR") {
+				return right, nil
+			}
+			if left == nil || right == nil {
+				return nil, nil
+			}
+			return e.op == "AND", nil
+		case "=", "<>", "<", "<=", ">", ">=":
+			left, err := c.eval(e.left, row)
+			if err != nil {
+				return nil, err
+			}
+			right, err := c.eval(e.right, row)
+			if err != nil {
+				return nil, err
+			}
+			if left == nil || right == nil {
+				return nil, nil
+			}
+			cmp := compareValues(left, right)
+			switch e.op {
+			case "=":
+				return cmp == 0, nil
+			case "<>":
+				return cmp != 0, nil
+			case "<":
+				return cmp < 0, nil
+			case "<=":
+				return cmp <= 0, nil
+			case ">":
+				return cmp > 0, nil
+			case ">=":
+				return cmp >= 0, nil
+			}
+		}
+	}
+
+	return nil, &DriverError{SqlState: "HYC00", Message: fmt.Sprintf("Unsupported expression at position %d", c.stmt.characterPosition(e.position()))}
+}
+
+func (c *evalContext) filter(rows []map[string]any) ([]map[string]any, error) {
+	if c.stmt.where == nil {
+		return rows, nil
+	}
+
+	result := make([]map[string]any, 0, len(rows))
+	for _, row := range rows {
+		matched, err := c.evalCondition(c.stmt.where, row)
+		if err != nil {
+			return nil, err
+		}
+		if matched == true {
+			result = append(result, row)
+		}
+	}
+
+	return result, nil
+}
+
+// Evaluates the statement over the rows of the tables of the FROM clause,
+// giving the names and the rows of the result set
+func (c *evalContext) evaluate(tables [][]map[string]any) ([]string, [][]any, error) {
+	parts, err := c.join(tables)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parts, err = c.filter(parts); err != nil {
+		return nil, nil, err
+	}
+	rows, err := c.groupRows(parts)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rows, err = c.sort(rows); err != nil {
+		return nil, nil, err
+	}
+	names, data, err := c.project(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	return names, c.slice(c.distinct(data)), nil
+}
+
+// Columns are named by their alias or otherwise by their text, except that
+// columns of joined tables are always named with their table's qualifier
+func (c *evalContext) columnNames() []string {
+	names := make([]string, len(c.stmt.columns))
+	for idx, column := range c.stmt.columns {
+		names[idx] = column.alias
+		if names[idx] != "" {
+			continue
+		}
+		names[idx] = column.text
+		if ref, ok := column.expr.(*columnRef); ok && len(c.stmt.joins) > 0 {
+			if key, err := c.resolveColumn(ref); err == nil {
+				names[idx] = key
+			}
+		}
+	}
+	return names
+}
+
+// The columns of SELECT *, which are those of every table even when none of
+// the rows that are left have them
+func (c *evalContext) starColumns() []string {
+	return c.starKeys
+}
+
+// Gives the schema's description of each column of the result that is a
+// column of a table, nil for the others
+func (c *evalContext) schemaColumns(names []string) []*desc {
+	columns := make([]*desc, len(names))
+	for idx, key := range names {
+		if c.stmt.columns != nil {
+			ref, ok := c.stmt.columns[idx].expr.(*columnRef)
+			if !ok {
+				continue
+			}
+			var err error
+			if key, err = c.resolveColumn(ref); err != nil {
+				continue
+			}
+		}
+		table, name := c.stmt.splitQualified(key)
+		if table < 0 {
+			table = 0
+		}
+		if table < len(c.schemas) {
+			columns[idx] = c.schemas[table].column(name)
+		}
+	}
+	return columns
+}
+
+// A row of the result before projection, which for statements with
+// aggregates is a group of rows represented by the first row of the group
+type resultRow struct {
+	row   map[string]any
+	group []map[string]any
+}
+
+func (c *evalContext) evalRow(e expr, row *resultRow) (any, error) {
+	c.group = row.group
+	defer func() { c.group = nil }()
+	return c.eval(e, row.row)
+}
+
+func resultRowMaps(rows []*resultRow) []map[string]any {
+	maps := make([]map[string]any, len(rows))
+	for idx, row := range rows {
+		maps[idx] = row.row
+	}
+	return maps
+}
+
+// Evaluates the select list for each row, giving the names of the columns and
+// the values of each row in the order of the select list
+func (c *evalContext) project(rows []*resultRow) ([]string, [][]any, error) {
+	result := make([][]any, 0, len(rows))
+
+	if c.stmt.columns == nil {
+		names := c.starColumns()
+		for _, row := range rows {
+			values := make([]any, len(names))
+			for idx, name := range names {
+				values[idx] = row.row[name]
+			}
+			result = append(result, values)
+		}
+		return names, result, nil
+	}
+
+	for _, row := range rows {
+		values := make([]any, len(c.stmt.columns))
+		for idx, column := range c.stmt.columns {
+			value, err := c.evalRow(column.expr, row)
+			if err != nil {
+				return nil, nil, err
+			}
+			values[idx] = value
+		}
+		result = append(result, values)
+	}
+
+	return c.columnNames(), result, nil
+}
+
+// Resolves an ORDER BY or GROUP BY term, which may refer to a column of the
+// select list by alias or by position, as well as to any column of the rows.
+// Positions can only be resolved for SELECT * once the rows are known, until
+// then nil is returned.
+func (c *evalContext) selectListExpr(clause string, e expr, rows []map[string]any) (expr, error) {
+	switch e := e.(type) {
+	case *literal:
+		number, ok := e.value.(json.Number)
+		if !ok {
+			break
+		}
+		position, err := number.Int64()
+		if err != nil {
+			break
+		}
+		var columns []expr
+		if c.stmt.columns != nil {
+			for _, column := range c.stmt.columns {
+				columns = append(columns, column.expr)
+			}
+		} else if rows != nil {
+			for _, name := range c.starColumns() {
+				columns = append(columns, &columnRef{pos: e.pos, name: name})
+			}
+		} else {
+			return nil, nil
+		}
+		if position < 1 || position > int64(len(columns)) {
+			return nil, &DriverError{SqlState: "42000", Message: fmt.Sprintf("%s position %d is not in the select list at position %d", clause, position, c.stmt.characterPosition(e.pos))}
+		}
+		return columns[position-1], nil
+	case *columnRef:
+		for _, column := range c.stmt.columns {
+			if column.alias == e.name {
+				return column.expr, nil
+			}
+		}
+		for _, column := range c.stmt.columns {
+			if column.alias != "" && strings.EqualFold(column.alias, e.name) {
+				return column.expr, nil
+			}
+		}
+	}
+	return e, nil
+}
+
+// The InvenTree part list can be ordered by these fields
+var orderingFields = map[string]string{
+	"pk":            "pk",
+	"ipn":           "IPN",
+	"name":          "name",
+	"creation_date": "creation_date",
+}
+
+// Translates the ORDER BY clause into the ordering argument of the part list
+// API, if every term of it can be
+func (c *evalContext) ordering() (string, bool) {
+	if c.stmt.orderBy == nil || c.stmt.isAggregate() || len(c.stmt.joins) > 0 {
+		return "", false
+	}
+
+	var fields []string
+	for _, term := range c.stmt.orderBy {
+		e, err := c.selectListExpr("ORDER BY", term.expr, nil)
+		if err != nil {
+			return "", false
+		}
+		ref, ok := e.(*columnRef)
+		if !ok {
+			return "", false
+		}
+		field, ok := orderingFields[strings.ToLower(ref.name)]
+		if !ok {
+			return "", false
+		}
+		if term.desc {
+			field = "-" + field
+		}
+		fields = append(fields, field)
+	}
+
+	return strings.Join(fields, ","), true
+}
+
+// Sorts the rows according to the ORDER BY clause. NULLs sort before any
+// other value, i.e. they come first in ascending order.
+func (c *evalContext) sort(rows []*resultRow) ([]*resultRow, error) {
+	if c.stmt.orderBy == nil || c.orderPushedDown {
+		return rows, nil
+	}
+
+	exprs := make([]expr, len(c.stmt.orderBy))
+	for idx, term := range c.stmt.orderBy {
+		e, err := c.selectListExpr("ORDER BY", term.expr, resultRowMaps(rows))
+		if err != nil {
+			return nil, err
+		}
+		exprs[idx] = e
+	}
+
+	type sortRow struct {
+		row    *resultRow
+		values []any
+	}
+	sortRows := make([]sortRow, len(rows))
+	for idx, row := range rows {
+		sortRows[idx] = sortRow{row: row, values: make([]any, len(exprs))}
+		for termIdx, e := range exprs {
+			value, err := c.evalRow(e, row)
+			if err != nil {
+				return nil, err
+			}
+			sortRows[idx].values[termIdx] = value
+		}
+	}
+
+	sort.SliceStable(sortRows, func(i, j int) bool {
+		for idx, term := range c.stmt.orderBy {
+			a, b := sortRows[i].values[idx], sortRows[j].values[idx]
+			var cmp int
+			switch {
+			case a == nil && b == nil:
+				continue
+			case a == nil:
+				cmp = -1
+			case b == nil:
+				cmp = 1
+			default:
+				cmp = compareValues(a, b)
+			}
+			if term.desc {
+				cmp = -cmp
+			}
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+		return false
+	})
+
+	result := make([]*resultRow, len(sortRows))
+	for idx, sortRow := range sortRows {
+		result[idx] = sortRow.row
+	}
+	return result, nil
+}
+
+// Removes duplicate rows for SELECT DISTINCT, keeping the first of each
+func (c *evalContext) distinct(data [][]any) [][]any {
+	if !c.stmt.distinct {
+		return data
+	}
+
+	seen := make(map[string]bool)
+	result := make([][]any, 0, len(data))
+	for _, values := range data {
+		key := valuesKey(values)
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, values)
+		}
+	}
+	return result
+}
+
+// Applies LIMIT and OFFSET to the rows
+func (c *evalContext) slice(data [][]any) [][]any {
+	if c.limitPushedDown {
+		return data
+	}
+
+	if offset := c.stmt.offset; offset != nil {
+		data = data[min(*offset, int64(len(data))):]
+	}
+	if limit := c.stmt.limit; limit != nil {
+		data = data[:min(*limit, int64(len(data)))]
+	}
+	return data
+}
+
+// Works out whether the condition pins down pk or IPN to a few values, which
+// allows the parts to be fetched directly rather than listing the whole
+// category, giving the column and the values. For parameter arrays the values
+// are those of every set of parameters, which all have to pin down the same
+// column.
+func (c *evalContext) keyLookup() (string, []string, bool) {
+	if c.paramSets == nil {
+		return c.setKeyLookup()
+	}
+
+	params := c.params
+	defer func() { c.params = params }()
+
+	var column string
+	var values []string
+	seen := make(map[string]bool)
+	for _, set := range c.paramSets {
+		c.params = set
+		setColumn, setValues, ok := c.setKeyLookup()
+		if !ok || (column != "" && setColumn != column) {
+			return "", nil, false
+		}
+		column = setColumn
+		for _, value := range setValues {
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	return column, values, true
+}
+
+// Finds a `pk = value`, `IPN = value` or `pk/IPN IN (values)` condition that
+// every matching row has to satisfy with the current set of parameters
+func (c *evalContext) setKeyLookup() (string, []string, bool) {
+	for _, condition := range conjuncts(c.stmt.where) {
+		var column *columnRef
+		var operands []expr
+		switch condition := condition.(type) {
+		case *binaryExpr:
+			if condition.op != "=" {
+				continue
+			}
+			var ok bool
+			if column, ok = condition.left.(*columnRef); ok {
+				operands = []expr{condition.right}
+			} else if column, ok = condition.right.(*columnRef); ok {
+				operands = []expr{condition.left}
+			}
+		case *inExpr:
+			if !condition.not {
+				column, _ = condition.operand.(*columnRef)
+				operands = condition.list
+			}
+		}
+		if column == nil || (column.name != "pk" && column.name != "IPN") {
+			continue
+		}
+
+		if values, ok := c.keyValues(column.name, operands); ok {
+			return column.name, values, true
+		}
+	}
+
+	return "", nil, false
+}
+
+func (c *evalContext) keyValues(column string, operands []expr) ([]string, bool) {
+	values := make([]string, 0, len(operands))
+	for _, operand := range operands {
+		switch operand.(type) {
+		case *literal, *paramRef:
+		default:
+			return nil, false
+		}
+		value, err := c.eval(operand, nil)
+		if err != nil {
+			return nil, false
+		}
+		switch value.(type) {
+		case string, json.Number:
+		default:
+			return nil, false
+		}
+		// Anything but an integer can't match a pk, but it is up to the
+		// filter to decide that
+		if column == "pk" {
+			pk, ok := toInteger(value)
+			if !ok {
+				return nil, false
+			}
+			value = strconv.FormatInt(pk, 10)
+		}
+		values = append(values, toString(value))
+	}
+	return values, true
+}
+
+// The types of the part fields a parameter is commonly compared against,
+// everything else is described as a string
+var columnHints = map[string]any{
+	"pk":             json.Number("0"),
+	"category":       json.Number("0"),
+	"in_stock":       floatNumber(0),
+	"creation_date":  sqlDate(""),
+	"last_stocktake": sqlDate(""),
+}
+
+func (stmt *selectStmt) exprHint(e expr) any {
+	switch e := e.(type) {
+	case *columnRef:
+		_, name := stmt.splitQualified(e.name)
+		return columnHints[strings.ToLower(name)]
+	case *castExpr:
+		return e.to.hint()
+	case *funcCall:
+		if function, ok := scalarFunctions[e.name]; ok {
+			return function.result
+		}
+		if e.name == "COUNT" {
+			return json.Number("0")
+		}
+	}
+	return nil
+}
+
+// Gives a value of the type each parameter marker is expected to have, going
+// by what it is compared against, for describing the parameters
+func (stmt *selectStmt) paramHints() []any {
+	hints := make([]any, stmt.numParams)
+	compare := func(operand expr, others ...expr) {
+		for _, other := range others {
+			if ref, ok := other.(*paramRef); ok && hints[ref.index] == nil {
+				hints[ref.index] = stmt.exprHint(operand)
+			}
+			if ref, ok := operand.(*paramRef); ok && hints[ref.index] == nil {
+				hints[ref.index] = stmt.exprHint(other)
+			}
+		}
+	}
+
+	var exprs []expr
+	for _, column := range stmt.columns {
+		exprs = append(exprs, column.expr)
+	}
+	for _, join := range stmt.joins {
+		exprs = append(exprs, join.on)
+	}
+	exprs = append(exprs, stmt.where, stmt.having)
+	exprs = append(exprs, stmt.groupBy...)
+	for _, term := range stmt.orderBy {
+		exprs = append(exprs, term.expr)
+	}
+	for _, e := range exprs {
+		walkExpr(e, func(e expr) {
+			switch e := e.(type) {
+			case *binaryExpr:
+				if _, ok := comparisonOperators[e.op]; ok {
+					compare(e.left, e.right)
+				}
+			case *inExpr:
+				compare(e.operand, e.list...)
+			case *betweenExpr:
+				compare(e.operand, e.low, e.high)
+			}
+		})
+	}
+
+	for idx := range hints {
+		if hints[idx] == nil {
+			hints[idx] = ""
+		}
+	}
+	return hints
+}
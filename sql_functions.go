@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type scalarFunction struct {
+	minArgs int
+	// -1 for any number of arguments
+	maxArgs int
+	// A value of the type the function always returns, nil if that depends
+	// on the arguments
+	result any
+	// Most functions return NULL when any of their arguments is NULL, those
+	// that don't get to see the NULLs
+	nullable bool
+	fn       func(c *evalContext, e *funcCall, args []any) (any, error)
+}
+
+func stringFunction(fn func(string) string) func(*evalContext, *funcCall, []any) (any, error) {
+	return func(c *evalContext, e *funcCall, args []any) (any, error) {
+		return fn(toString(args[0])), nil
+	}
+}
+
+// Functions with ODBC names (UCASE, LCASE, IFNULL, etc.) are those of the ODBC
+// canonical function set, so that {fn ...} escape sequences work as expected
+var scalarFunctions = map[string]*scalarFunction{
+	"UPPER": {minArgs: 1, maxArgs: 1, result: "", fn: stringFunction(strings.ToUpper)},
+	"UCASE": {minArgs: 1, maxArgs: 1, result: "", fn: stringFunction(strings.ToUpper)},
+	"LOWER": {minArgs: 1, maxArgs: 1, result: "", fn: stringFunction(strings.ToLower)},
+	"LCASE": {minArgs: 1, maxArgs: 1, result: "", fn: stringFunction(strings.ToLower)},
+	"TRIM":  {minArgs: 1, maxArgs: 1, result: "", fn: stringFunction(func(s string) string { return strings.Trim(s, " ") })},
+	"LTRIM": {minArgs: 1, maxArgs: 1, result: "", fn: stringFunction(func(s string) string { return strings.TrimLeft(s, " ") })},
+	"RTRIM": {minArgs: 1, maxArgs: 1, result: "", fn: stringFunction(func(s string) string { return strings.TrimRight(s, " ") })},
+	// As in ODBC, LENGTH doesn't count trailing blanks while CHAR_LENGTH does
+	"LENGTH":      {minArgs: 1, maxArgs: 1, result: json.Number("0"), fn: evalLength(true)},
+	"CHAR_LENGTH": {minArgs: 1, maxArgs: 1, result: json.Number("0"), fn: evalLength(false)},
+	"CONCAT":      {minArgs: 1, maxArgs: -1, result: "", nullable: true, fn: evalConcat},
+	"SUBSTRING":   {minArgs: 2, maxArgs: 3, result: "", fn: evalSubstring},
+	"REPLACE":     {minArgs: 3, maxArgs: 3, result: "", fn: evalReplace},
+	"COALESCE":    {minArgs: 1, maxArgs: -1, nullable: true, fn: evalCoalesce},
+	"IFNULL":      {minArgs: 2, maxArgs: 2, nullable: true, fn: evalCoalesce},
+	"ROUND":       {minArgs: 1, maxArgs: 2, fn: evalRound},
+}
+
+func evalLength(trimTrailing bool) func(*evalContext, *funcCall, []any) (any, error) {
+	return func(c *evalContext, e *funcCall, args []any) (any, error) {
+		value := toString(args[0])
+		if trimTrailing {
+			value = strings.TrimRight(value, " ")
+		}
+		return json.Number(strconv.Itoa(len([]rune(value)))), nil
+	}
+}
+
+// Unlike ||, CONCAT skips NULLs, which makes it convenient for building values
+// out of parameters that not every part has
+func evalConcat(c *evalContext, e *funcCall, args []any) (any, error) {
+	var result strings.Builder
+	for _, arg := range args {
+		if arg != nil {
+			result.WriteString(toString(arg))
+		}
+	}
+	return result.String(), nil
+}
+
+func (c *evalContext) integerArgument(e *funcCall, idx int, value any) (int64, error) {
+	if i, ok := toInteger(value); ok {
+		return i, nil
+	}
+	if f, ok := toNumber(value); ok && f == math.Trunc(f) {
+		return int64(f), nil
+	}
+	return 0, &DriverError{SqlState: "22018", Message: fmt.Sprintf("Invalid integer value for argument %d of %s at position %d: %v", idx+1, e.name, c.stmt.characterPosition(e.pos), value)}
+}
+
+// SUBSTRING(string, start[, length]), where the first character is at 1
+func evalSubstring(c *evalContext, e *funcCall, args []any) (any, error) {
+	runes := []rune(toString(args[0]))
+	start, err := c.integerArgument(e, 1, args[1])
+	if err != nil {
+		return nil, err
+	}
+	end := int64(len(runes)) + 1
+	if len(args) > 2 {
+		length, err := c.integerArgument(e, 2, args[2])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, &DriverError{SqlState: "22011", Message: fmt.Sprintf("Negative length for SUBSTRING at position %d: %d", c.stmt.characterPosition(e.pos), length)}
+		}
+		end = min(end, start+length)
+	}
+	if start < 1 {
+		start = 1
+	}
+	if start >= end {
+		return "", nil
+	}
+	return string(runes[start-1 : end-1]), nil
+}
+
+func evalReplace(c *evalContext, e *funcCall, args []any) (any, error) {
+	value, old := toString(args[0]), toString(args[1])
+	if old == "" {
+		return value, nil
+	}
+	return strings.ReplaceAll(value, old, toString(args[2])), nil
+}
+
+func evalCoalesce(c *evalContext, e *funcCall, args []any) (any, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+// ROUND(number[, places]) rounds half away from zero, keeping integers as
+// integers
+func evalRound(c *evalContext, e *funcCall, args []any) (any, error) {
+	var places int64
+	if len(args) > 1 {
+		var err error
+		if places, err = c.integerArgument(e, 1, args[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	if i, ok := toInteger(args[0]); ok && isNumeric(args[0]) && places >= 0 {
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	}
+	number, ok := toNumber(args[0])
+	if !ok {
+		return nil, &DriverError{SqlState: "22018", Message: fmt.Sprintf("Invalid numeric value for ROUND at position %d: %v", c.stmt.characterPosition(e.pos), args[0])}
+	}
+	scale := math.Pow(10, float64(places))
+	return floatNumber(math.Round(number*scale) / scale), nil
+}
+
+func (c *evalContext) evalFunction(e *funcCall, row map[string]any) (any, error) {
+	if aggregateFunctions[e.name] {
+		return c.evalAggregate(e)
+	}
+	function, ok := scalarFunctions[e.name]
+	if !ok {
+		return nil, &DriverError{SqlState: "HYC00", Message: fmt.Sprintf("Unsupported function at position %d: %s", c.stmt.characterPosition(e.pos), e.name)}
+	}
+
+	args := make([]any, len(e.args))
+	for idx, arg := range e.args {
+		value, err := c.eval(arg, row)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil && !function.nullable {
+			return nil, nil
+		}
+		args[idx] = value
+	}
+
+	return function.fn(c, e, args)
+}
+
+type castType struct {
+	// string, integer, float, date or timestamp
+	kind string
+	// Maximum number of characters for strings, 0 if not limited
+	length int
+}
+
+type castExpr struct {
+	pos     int
+	operand expr
+	to      castType
+}
+
+func (e *castExpr) position() int { return e.pos }
+
+// Type names accepted by CAST, as well as the SQL_ type names used by the
+// ODBC CONVERT function
+var castTypes = map[string]string{
+	"CHAR":             "string",
+	"CHARACTER":        "string",
+	"VARCHAR":          "string",
+	"TEXT":             "string",
+	"SQL_CHAR":         "string",
+	"SQL_VARCHAR":      "string",
+	"SQL_LONGVARCHAR":  "string",
+	"SQL_WCHAR":        "string",
+	"SQL_WVARCHAR":     "string",
+	"SQL_WLONGVARCHAR": "string",
+	"INT":              "integer",
+	"INTEGER":          "integer",
+	"SMALLINT":         "integer",
+	"BIGINT":           "integer",
+	"TINYINT":          "integer",
+	"SQL_INTEGER":      "integer",
+	"SQL_SMALLINT":     "integer",
+	"SQL_BIGINT":       "integer",
+	"SQL_TINYINT":      "integer",
+	"DOUBLE":           "float",
+	"FLOAT":            "float",
+	"REAL":             "float",
+	"NUMERIC":          "float",
+	"DECIMAL":          "float",
+	"SQL_DOUBLE":       "float",
+	"SQL_FLOAT":        "float",
+	"SQL_REAL":         "float",
+	"SQL_NUMERIC":      "float",
+	"SQL_DECIMAL":      "float",
+
+	"DATE":               "date",
+	"SQL_DATE":           "date",
+	"SQL_TYPE_DATE":      "date",
+	"TIMESTAMP":          "timestamp",
+	"DATETIME":           "timestamp",
+	"SQL_TIMESTAMP":      "timestamp",
+	"SQL_TYPE_TIMESTAMP": "timestamp",
+}
+
+func (t castType) hint() any {
+	switch t.kind {
+	case "integer":
+		return json.Number("0")
+	case "float":
+		return floatNumber(0)
+	case "date":
+		return sqlDate("")
+	case "timestamp":
+		return sqlTimestamp("")
+	}
+	return ""
+}
+
+func (c *evalContext) evalCast(e *castExpr, row map[string]any) (any, error) {
+	value, err := c.eval(e.operand, row)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	invalid := func() error {
+		return &DriverError{SqlState: "22018", Message: fmt.Sprintf("Invalid character value for cast at position %d: %v", c.stmt.characterPosition(e.pos), value)}
+	}
+
+	switch e.to.kind {
+	case "integer":
+		if i, ok := toInteger(value); ok {
+			return json.Number(strconv.FormatInt(i, 10)), nil
+		}
+		number, ok := toNumber(value)
+		if !ok {
+			return nil, invalid()
+		}
+		if number >= math.MaxInt64 || number <= math.MinInt64 || math.IsNaN(number) {
+			return nil, &DriverError{SqlState: "22003", Message: fmt.Sprintf("Numeric value out of range for cast at position %d: %v", c.stmt.characterPosition(e.pos), value)}
+		}
+		return json.Number(strconv.FormatInt(int64(number), 10)), nil
+	case "float":
+		number, ok := toNumber(value)
+		if !ok {
+			return nil, invalid()
+		}
+		return floatNumber(number), nil
+	case "date", "timestamp":
+		t, ok := toTime(value)
+		if !ok {
+			return nil, invalid()
+		}
+		if e.to.kind == "date" {
+			return newDate(t), nil
+		}
+		return newTimestamp(t), nil
+	default:
+		result := toString(value)
+		if runes := []rune(result); e.to.length > 0 && len(runes) > e.to.length {
+			result = string(runes[:e.to.length])
+		}
+		return result, nil
+	}
+}
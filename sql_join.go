@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The tables of the FROM clause, in the order they are joined
+func (stmt *selectStmt) tables() []*tableRef {
+	tables := []*tableRef{stmt.from}
+	for _, join := range stmt.joins {
+		tables = append(tables, join.table)
+	}
+	return tables
+}
+
+// Splits a qualified column name such as r.IPN into the index of the table it
+// is qualified by and the unqualified name, giving -1 when it isn't qualified
+func (stmt *selectStmt) splitQualified(name string) (int, string) {
+	if len(stmt.joins) == 0 {
+		return -1, name
+	}
+	lower := strings.ToLower(name)
+	for idx, table := range stmt.tables() {
+		if prefix := strings.ToLower(table.qualifier()) + "."; strings.HasPrefix(lower, prefix) {
+			return idx, name[len(prefix):]
+		}
+	}
+	return -1, name
+}
+
+// Prefixes the keys of the rows of a joined table with the table's qualifier
+func qualifyColumns(qualifier string, rows []map[string]any) []map[string]any {
+	result := make([]map[string]any, len(rows))
+	for idx, row := range rows {
+		qualified := make(map[string]any, len(row))
+		for key, value := range row {
+			qualified[qualifier+"."+key] = value
+		}
+		result[idx] = qualified
+	}
+	return result
+}
+
+// Gives the index of the table an expression takes its columns from, -1 when
+// it doesn't reference any columns and -2 when it references several tables
+// or columns that can't be attributed to a table
+func (c *evalContext) tableOf(e expr) int {
+	table := -1
+	for _, name := range referencedColumns(e) {
+		key, err := c.resolveColumn(&columnRef{name: name})
+		if err != nil {
+			return -2
+		}
+		idx, ok := c.keyTables[key]
+		if !ok {
+			idx, _ = c.stmt.splitQualified(name)
+		}
+		if idx < 0 || (table >= 0 && idx != table) {
+			return -2
+		}
+		table = idx
+	}
+	return table
+}
+
+// Finds the equalities of an ON condition between an expression over the
+// rows joined so far and one over the table being joined, which are what the
+// rows are hashed on
+func (c *evalContext) equiJoinKeys(join *joinClause, table int) (left []expr, right []expr) {
+	for _, condition := range conjuncts(join.on) {
+		binary, ok := condition.(*binaryExpr)
+		if !ok || binary.op != "=" || containsAggregate(binary) {
+			continue
+		}
+		l, r := c.tableOf(binary.left), c.tableOf(binary.right)
+		switch {
+		case l >= 0 && l < table && r == table:
+			left, right = append(left, binary.left), append(right, binary.right)
+		case r >= 0 && r < table && l == table:
+			left, right = append(left, binary.right), append(right, binary.left)
+		}
+	}
+	return left, right
+}
+
+func (c *evalContext) joinKey(exprs []expr, row map[string]any) (string, bool, error) {
+	values := make([]any, len(exprs))
+	for idx, e := range exprs {
+		value, err := c.eval(e, row)
+		if err != nil || value == nil {
+			return "", false, err
+		}
+		values[idx] = value
+	}
+	return valuesKey(values), true, nil
+}
+
+// Joins the rows of the tables of the FROM clause, which must have had their
+// columns qualified. Each join hashes the rows of the table being joined on
+// the equalities of its ON condition and then probes that with the rows joined
+// so far, falling back to comparing every pair of rows when there are no such
+// equalities. The whole ON condition is evaluated for every candidate pair.
+func (c *evalContext) join(tables [][]map[string]any) ([]map[string]any, error) {
+	rows := tables[0]
+	for idx, join := range c.stmt.joins {
+		table := idx + 1
+		leftKeys, rightKeys := c.equiJoinKeys(join, table)
+
+		index := make(map[string][]map[string]any)
+		if len(rightKeys) > 0 {
+			for _, row := range tables[table] {
+				key, ok, err := c.joinKey(rightKeys, row)
+				if err != nil {
+					return nil, err
+				}
+				// NULLs never compare equal, so these rows can't match
+				if ok {
+					index[key] = append(index[key], row)
+				}
+			}
+		}
+
+		var result []map[string]any
+		for _, row := range rows {
+			candidates := tables[table]
+			if len(leftKeys) > 0 {
+				key, ok, err := c.joinKey(leftKeys, row)
+				if err != nil {
+					return nil, err
+				}
+				candidates = nil
+				if ok {
+					candidates = index[key]
+				}
+			}
+
+			matched := false
+			for _, other := range candidates {
+				joined := make(map[string]any, len(row)+len(other))
+				for key, value := range row {
+					joined[key] = value
+				}
+				for key, value := range other {
+					joined[key] = value
+				}
+				on, err := c.evalCondition(join.on, joined)
+				if err != nil {
+					return nil, err
+				}
+				if on == true {
+					result = append(result, joined)
+					matched = true
+				}
+			}
+			// Without a match the columns of the joined table are NULL
+			if !matched && join.left {
+				result = append(result, row)
+			}
+		}
+		rows = result
+	}
+
+	return rows, nil
+}
+
+func (c *evalContext) ambiguousColumn(ref *columnRef) error {
+	return &DriverError{SqlState: "42000", Message: fmt.Sprintf("Ambiguous column name at position %d: %s", c.stmt.characterPosition(ref.pos), ref.name)}
+}
@@ -0,0 +1,1138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenString
+	tokenNumber
+	tokenParam
+	tokenOperator
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of statement"
+	case tokenIdent:
+		return "identifier"
+	case tokenQuotedIdent:
+		return "quoted identifier"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number"
+	case tokenParam:
+		return "parameter marker"
+	case tokenOperator:
+		return "operator"
+	default:
+		return fmt.Sprintf("??? (%d)", int(k))
+	}
+}
+
+type token struct {
+	kind tokenKind
+	// For strings and quoted identifiers this is the unquoted value
+	text string
+	// Byte offsets into the statement text
+	pos int
+	end int
+}
+
+type parseError struct {
+	sql     string
+	pos     int
+	message string
+}
+
+// Positions are reported as 1-based character offsets, which is what a user
+// counting along the statement would expect.
+func characterPosition(sql string, pos int) int {
+	return utf8.RuneCountInString(sql[:min(pos, len(sql))]) + 1
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", characterPosition(e.sql, e.pos), e.message)
+}
+
+var reservedWords = map[string]bool{
+	"AND":      true,
+	"AS":       true,
+	"ASC":      true,
+	"BETWEEN":  true,
+	"BY":       true,
+	"CROSS":    true,
+	"DESC":     true,
+	"DISTINCT": true,
+	"ESCAPE":   true,
+	"FROM":     true,
+	"FULL":     true,
+	"GROUP":    true,
+	"HAVING":   true,
+	"IN":       true,
+	"INNER":    true,
+	"IS":       true,
+	"JOIN":     true,
+	"LEFT":     true,
+	"LIKE":     true,
+	"LIMIT":    true,
+	"NOT":      true,
+	"NULL":     true,
+	"OFFSET":   true,
+	"ON":       true,
+	"OR":       true,
+	"ORDER":    true,
+	"OUTER":    true,
+	"RIGHT":    true,
+	"SELECT":   true,
+	"WHERE":    true,
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+var operators = []string{"<>", "!=", "<=", ">=", "||", "=", "<", ">", "(", ")", ",", "*", "+", "-", "/", ".", "{", "}", ";"}
+
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+
+	pos := 0
+	for pos < len(sql) {
+		r, size := utf8.DecodeRuneInString(sql[pos:])
+		start := pos
+
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+			continue
+		case r == '-' && strings.HasPrefix(sql[pos:], "--"):
+			if end := strings.IndexByte(sql[pos:], '\n'); end >= 0 {
+				pos += end + 1
+			} else {
+				pos = len(sql)
+			}
+			continue
+		case r == '\'' || r == '"':
+			var value strings.Builder
+			pos += size
+			for {
+				end := strings.IndexRune(sql[pos:], r)
+				if end < 0 {
+					return nil, &parseError{sql: sql, pos: start, message: fmt.Sprintf("unterminated %s", map[rune]string{'\'': "string", '"': "quoted identifier"}[r])}
+				}
+				value.WriteString(sql[pos : pos+end])
+				pos += end + 1
+				// A doubled quote is an escaped quote
+				if pos < len(sql) && rune(sql[pos]) == r {
+					value.WriteRune(r)
+					pos += 1
+					continue
+				}
+				break
+			}
+			kind := tokenString
+			if r == '"' {
+				kind = tokenQuotedIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: value.String(), pos: start, end: pos})
+		case r == '?':
+			pos += size
+			tokens = append(tokens, token{kind: tokenParam, text: "?", pos: start, end: pos})
+		case (r < utf8.RuneSelf && isDigit(byte(r))) || (r == '.' && pos+1 < len(sql) && isDigit(sql[pos+1])):
+			for pos < len(sql) && isDigit(sql[pos]) {
+				pos += 1
+			}
+			if pos < len(sql) && sql[pos] == '.' {
+				pos += 1
+				for pos < len(sql) && isDigit(sql[pos]) {
+					pos += 1
+				}
+			}
+			if pos < len(sql) && (sql[pos] == 'e' || sql[pos] == 'E') {
+				exp := pos + 1
+				if exp < len(sql) && (sql[exp] == '+' || sql[exp] == '-') {
+					exp += 1
+				}
+				if exp < len(sql) && isDigit(sql[exp]) {
+					pos = exp
+					for pos < len(sql) && isDigit(sql[pos]) {
+						pos += 1
+					}
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: sql[start:pos], pos: start, end: pos})
+		case isIdentStart(r):
+			for pos < len(sql) {
+				r, size := utf8.DecodeRuneInString(sql[pos:])
+				if isIdentPart(r) {
+					pos += size
+					continue
+				}
+				// Dotted names such as parameter.Package are a single identifier
+				if r == '.' && pos+1 < len(sql) {
+					if next, _ := utf8.DecodeRuneInString(sql[pos+1:]); isIdentPart(next) {
+						pos += 1
+						continue
+					}
+				}
+				break
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: sql[start:pos], pos: start, end: pos})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(sql[pos:], op) {
+					pos += len(op)
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start, end: pos})
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &parseError{sql: sql, pos: start, message: fmt.Sprintf("unexpected character %q", r)}
+			}
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(sql), end: len(sql)})
+
+	return tokens, nil
+}
+
+type expr interface {
+	// position of the expression in the statement text, used for diagnostics
+	position() int
+}
+
+type columnRef struct {
+	pos  int
+	name string
+}
+
+type literal struct {
+	pos   int
+	value any
+}
+
+type paramRef struct {
+	pos   int
+	index int
+}
+
+type unaryExpr struct {
+	pos     int
+	op      string
+	operand expr
+}
+
+type binaryExpr struct {
+	pos   int
+	op    string
+	left  expr
+	right expr
+}
+
+type isNullExpr struct {
+	pos     int
+	operand expr
+	not     bool
+}
+
+type likeExpr struct {
+	pos     int
+	operand expr
+	pattern expr
+	// nil when there is no escape clause
+	escape expr
+	not    bool
+}
+
+type inExpr struct {
+	pos     int
+	operand expr
+	list    []expr
+	not     bool
+}
+
+type betweenExpr struct {
+	pos     int
+	operand expr
+	low     expr
+	high    expr
+	not     bool
+}
+
+func (e *columnRef) position() int   { return e.pos }
+func (e *literal) position() int     { return e.pos }
+func (e *paramRef) position() int    { return e.pos }
+func (e *unaryExpr) position() int   { return e.pos }
+func (e *binaryExpr) position() int  { return e.pos }
+func (e *isNullExpr) position() int  { return e.pos }
+func (e *likeExpr) position() int    { return e.pos }
+func (e *inExpr) position() int      { return e.pos }
+func (e *betweenExpr) position() int { return e.pos }
+func (e *funcCall) position() int    { return e.pos }
+
+type funcCall struct {
+	pos  int
+	name string
+	args []expr
+	// COUNT(*)
+	star bool
+	// Aggregates over distinct values, e.g. COUNT(DISTINCT x)
+	distinct bool
+}
+
+type selectColumn struct {
+	expr  expr
+	alias string
+	// The text of the expression as written, used to name unaliased columns
+	text string
+}
+
+type tableRef struct {
+	pos   int
+	name  string
+	alias string
+}
+
+// Columns of joined tables are qualified by the table's alias, or by its name
+// when it has no alias
+func (t *tableRef) qualifier() string {
+	if t.alias != "" {
+		return t.alias
+	}
+	return t.name
+}
+
+type joinClause struct {
+	pos   int
+	table *tableRef
+	on    expr
+	// LEFT [OUTER] JOIN rather than [INNER] JOIN
+	left bool
+}
+
+type orderTerm struct {
+	expr expr
+	desc bool
+}
+
+type selectStmt struct {
+	sql      string
+	distinct bool
+	// nil when selecting *
+	columns []*selectColumn
+	from    *tableRef
+	joins   []*joinClause
+	where   expr
+	groupBy []expr
+	having  expr
+	orderBy []*orderTerm
+	limit   *int64
+	offset  *int64
+	// Number of ? markers in the statement
+	numParams int
+}
+
+func (stmt *selectStmt) characterPosition(pos int) int {
+	return characterPosition(stmt.sql, pos)
+}
+
+type parser struct {
+	sql       string
+	tokens    []token
+	current   int
+	numParams int
+}
+
+func parseSQL(sql string) (*selectStmt, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{sql: sql, tokens: tokens}
+
+	return p.parseStatement()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.current]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.current]
+	if tok.kind != tokenEOF {
+		p.current += 1
+	}
+	return tok
+}
+
+func (p *parser) describe(tok token) string {
+	switch tok.kind {
+	case tokenEOF:
+		return tok.kind.String()
+	case tokenString:
+		return fmt.Sprintf("string '%s'", tok.text)
+	case tokenQuotedIdent:
+		return fmt.Sprintf("identifier \"%s\"", tok.text)
+	default:
+		return fmt.Sprintf("'%s'", tok.text)
+	}
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return &parseError{sql: p.sql, pos: tok.pos, message: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expected(what string) error {
+	tok := p.peek()
+	return p.errorf(tok, "%s expected, got %s", what, p.describe(tok))
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && strings.EqualFold(tok.text, keyword)
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(keyword) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.expected(keyword)
+	}
+	return nil
+}
+
+func (p *parser) isOperator(op string) bool {
+	tok := p.peek()
+	return tok.kind == tokenOperator && tok.text == op
+}
+
+func (p *parser) acceptOperator(op string) bool {
+	if p.isOperator(op) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectOperator(op string) error {
+	if !p.acceptOperator(op) {
+		return p.expected(fmt.Sprintf("'%s'", op))
+	}
+	return nil
+}
+
+func (p *parser) parseStatement() (*selectStmt, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+
+	stmt := &selectStmt{sql: p.sql}
+
+	if p.acceptKeyword("DISTINCT") {
+		stmt.distinct = true
+	} else {
+		p.acceptKeyword("ALL")
+	}
+
+	if !p.acceptOperator("*") {
+		for {
+			column, err := p.parseSelectColumn()
+			if err != nil {
+				return nil, err
+			}
+			stmt.columns = append(stmt.columns, column)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	table, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+	stmt.from = table
+
+	for {
+		join, err := p.parseJoin()
+		if err != nil {
+			return nil, err
+		}
+		if join == nil {
+			break
+		}
+		for _, other := range stmt.tables() {
+			if strings.EqualFold(other.qualifier(), join.table.qualifier()) {
+				return nil, p.errorf(token{pos: join.table.pos}, "duplicate table name %s, use an alias", join.table.qualifier())
+			}
+		}
+		stmt.joins = append(stmt.joins, join)
+	}
+
+	if p.acceptKeyword("WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			stmt.groupBy = append(stmt.groupBy, e)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("HAVING") {
+		if stmt.having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		for {
+			term := &orderTerm{}
+			if term.expr, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+			if p.acceptKeyword("DESC") {
+				term.desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, term)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+	}
+
+	if p.acceptKeyword("LIMIT") {
+		if stmt.limit, err = p.parseCount("LIMIT"); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("OFFSET") {
+		if stmt.offset, err = p.parseCount("OFFSET"); err != nil {
+			return nil, err
+		}
+	}
+
+	for p.acceptOperator(";") {
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %s", p.describe(tok))
+	}
+
+	stmt.numParams = p.numParams
+
+	return stmt, nil
+}
+
+func (p *parser) parseSelectColumn() (*selectColumn, error) {
+	start := p.peek()
+	expression, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	column := &selectColumn{
+		expr: expression,
+		text: p.sql[start.pos:p.tokens[p.current-1].end],
+	}
+	if ref, ok := expression.(*columnRef); ok {
+		column.text = ref.name
+	}
+
+	if p.acceptKeyword("AS") {
+		tok := p.peek()
+		if tok.kind != tokenIdent && tok.kind != tokenQuotedIdent && tok.kind != tokenString {
+			return nil, p.expected("column alias")
+		}
+		p.next()
+		column.alias = tok.text
+	} else if tok := p.peek(); tok.kind == tokenQuotedIdent || (tok.kind == tokenIdent && !reservedWords[strings.ToUpper(tok.text)]) {
+		p.next()
+		column.alias = tok.text
+	}
+
+	return column, nil
+}
+
+// Category names are paths such as Electronics/Passives/Resistors, which
+// are accepted unquoted as long as there is no whitespace in them.
+func (p *parser) parseTableName() (*tableRef, error) {
+	tok := p.peek()
+	switch {
+	case tok.kind == tokenQuotedIdent:
+		p.next()
+		return &tableRef{pos: tok.pos, name: tok.text}, nil
+	case tok.kind == tokenIdent && !reservedWords[strings.ToUpper(tok.text)]:
+	default:
+		return nil, p.expected("table name")
+	}
+
+	p.next()
+	end := tok.end
+	for {
+		next := p.peek()
+		if next.pos != end {
+			break
+		}
+		if next.kind == tokenIdent || next.kind == tokenNumber || (next.kind == tokenOperator && strings.Contains("/-.", next.text)) {
+			p.next()
+			end = next.end
+			continue
+		}
+		break
+	}
+
+	return &tableRef{pos: tok.pos, name: p.sql[tok.pos:end]}, nil
+}
+
+func (p *parser) parseTableRef() (*tableRef, error) {
+	table, err := p.parseTableName()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword("AS") {
+		tok := p.peek()
+		if tok.kind != tokenQuotedIdent && (tok.kind != tokenIdent || reservedWords[strings.ToUpper(tok.text)]) {
+			return nil, p.expected("table alias")
+		}
+		p.next()
+		table.alias = tok.text
+	} else if tok := p.peek(); tok.kind == tokenQuotedIdent || (tok.kind == tokenIdent && !reservedWords[strings.ToUpper(tok.text)]) {
+		p.next()
+		table.alias = tok.text
+	}
+
+	return table, nil
+}
+
+// Parses [INNER] JOIN or LEFT [OUTER] JOIN, returning nil when there is no
+// further join
+func (p *parser) parseJoin() (*joinClause, error) {
+	tok := p.peek()
+	join := &joinClause{pos: tok.pos}
+	switch {
+	case p.isKeyword("RIGHT") || p.isKeyword("FULL") || p.isKeyword("CROSS"):
+		return nil, p.errorf(tok, "%s JOIN is not supported", strings.ToUpper(tok.text))
+	case p.acceptKeyword("LEFT"):
+		p.acceptKeyword("OUTER")
+		join.left = true
+		if err := p.expectKeyword("JOIN"); err != nil {
+			return nil, err
+		}
+	case p.acceptKeyword("INNER"):
+		if err := p.expectKeyword("JOIN"); err != nil {
+			return nil, err
+		}
+	case p.acceptKeyword("JOIN"):
+	default:
+		return nil, nil
+	}
+
+	var err error
+	if join.table, err = p.parseTableRef(); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("ON"); err != nil {
+		return nil, err
+	}
+	if join.on, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+
+	return join, nil
+}
+
+func (p *parser) parseCount(clause string) (*int64, error) {
+	tok := p.peek()
+	if tok.kind != tokenNumber {
+		return nil, p.expected(fmt.Sprintf("row count after %s", clause))
+	}
+	p.next()
+	value, err := strconv.ParseInt(tok.text, 10, 64)
+	if err != nil || value < 0 {
+		return nil, p.errorf(tok, "invalid row count for %s: %s", clause, tok.text)
+	}
+	return &value, nil
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		tok := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{pos: tok.pos, op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		tok := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{pos: tok.pos, op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.isKeyword("NOT") {
+		tok := p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{pos: tok.pos, op: "NOT", operand: operand}, nil
+	}
+	return p.parsePredicate()
+}
+
+var comparisonOperators = map[string]string{
+	"=":  "=",
+	"<>": "<>",
+	"!=": "<>",
+	"<":  "<",
+	"<=": "<=",
+	">":  ">",
+	">=": ">=",
+}
+
+func (p *parser) parsePredicate() (expr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if op, ok := comparisonOperators[tok.text]; ok && tok.kind == tokenOperator {
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryExpr{pos: tok.pos, op: op, left: left, right: right}, nil
+	}
+
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &isNullExpr{pos: tok.pos, operand: left, not: not}, nil
+	}
+
+	not := false
+	if next := p.tokens[min(p.current+1, len(p.tokens)-1)]; p.isKeyword("NOT") && next.kind == tokenIdent {
+		switch strings.ToUpper(next.text) {
+		case "LIKE", "IN", "BETWEEN":
+			p.next()
+			not = true
+		}
+	}
+
+	switch {
+	case p.acceptKeyword("LIKE"):
+		like := &likeExpr{pos: tok.pos, operand: left, not: not}
+		if like.pattern, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+		if like.escape, err = p.parseEscape(); err != nil {
+			return nil, err
+		}
+		return like, nil
+	case p.acceptKeyword("IN"):
+		in := &inExpr{pos: tok.pos, operand: left, not: not}
+		if err := p.expectOperator("("); err != nil {
+			return nil, err
+		}
+		for {
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			in.list = append(in.list, item)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+		return in, nil
+	case p.acceptKeyword("BETWEEN"):
+		between := &betweenExpr{pos: tok.pos, operand: left, not: not}
+		if between.low, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		if between.high, err = p.parseOperand(); err != nil {
+			return nil, err
+		}
+		return between, nil
+	}
+
+	return left, nil
+}
+
+// Parses the optional escape character of a LIKE predicate, given either as
+// ESCAPE 'c' or using the ODBC escape sequence {escape 'c'}
+func (p *parser) parseEscape() (expr, error) {
+	if p.acceptKeyword("ESCAPE") {
+		return p.parseOperand()
+	}
+
+	if next := p.tokens[min(p.current+1, len(p.tokens)-1)]; !p.isOperator("{") || next.kind != tokenIdent || !strings.EqualFold(next.text, "escape") {
+		return nil, nil
+	}
+	p.next()
+	p.next()
+	escape, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectOperator("}"); err != nil {
+		return nil, err
+	}
+	return escape, nil
+}
+
+var aggregateFunctions = map[string]bool{
+	"AVG":   true,
+	"COUNT": true,
+	"MAX":   true,
+	"MIN":   true,
+	"SUM":   true,
+}
+
+// Parses the arguments of a function call, the opening parenthesis having
+// been consumed already
+func (p *parser) parseCall(name token) (expr, error) {
+	call := &funcCall{pos: name.pos, name: strings.ToUpper(name.text)}
+	aggregate := aggregateFunctions[call.name]
+	scalar, ok := scalarFunctions[call.name]
+
+	switch {
+	case call.name == "CAST" || call.name == "CONVERT":
+		return p.parseCast(name)
+	case !aggregate && !ok:
+		return nil, p.errorf(name, "unknown function %s", name.text)
+	}
+
+	if tok := p.peek(); p.acceptOperator("*") {
+		if call.name != "COUNT" {
+			return nil, p.errorf(tok, "'*' is only allowed in COUNT(*)")
+		}
+		call.star = true
+	} else if !p.isOperator(")") {
+		if aggregate && p.acceptKeyword("DISTINCT") {
+			call.distinct = true
+		}
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+	}
+	if err := p.expectOperator(")"); err != nil {
+		return nil, err
+	}
+
+	if aggregate && !call.star && len(call.args) != 1 {
+		return nil, p.errorf(name, "%s takes exactly one argument", call.name)
+	}
+	if scalar != nil && (len(call.args) < scalar.minArgs || (scalar.maxArgs >= 0 && len(call.args) > scalar.maxArgs)) {
+		switch {
+		case scalar.minArgs == scalar.maxArgs:
+			return nil, p.errorf(name, "%s takes %d argument(s)", call.name, scalar.minArgs)
+		case scalar.maxArgs < 0:
+			return nil, p.errorf(name, "%s takes at least %d argument(s)", call.name, scalar.minArgs)
+		default:
+			return nil, p.errorf(name, "%s takes %d to %d arguments", call.name, scalar.minArgs, scalar.maxArgs)
+		}
+	}
+
+	return call, nil
+}
+
+// Parses CAST(expr AS type) and the ODBC CONVERT(expr, SQL_type)
+func (p *parser) parseCast(name token) (expr, error) {
+	cast := &castExpr{pos: name.pos}
+	var err error
+	if cast.operand, err = p.parseExpr(); err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(name.text, "CAST") {
+		err = p.expectKeyword("AS")
+	} else {
+		err = p.expectOperator(",")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	kind, ok := castTypes[strings.ToUpper(tok.text)]
+	if tok.kind != tokenIdent || !ok {
+		return nil, p.expected("data type")
+	}
+	p.next()
+	cast.to.kind = kind
+	if strings.EqualFold(tok.text, "DOUBLE") {
+		p.acceptKeyword("PRECISION")
+	}
+	// The length only matters for strings, but precision and scale are
+	// accepted for all types
+	if p.acceptOperator("(") {
+		for idx := 0; idx < 2; idx++ {
+			count, err := p.parseCount("data type")
+			if err != nil {
+				return nil, err
+			}
+			if idx == 0 && kind == "string" {
+				cast.to.length = int(*count)
+			}
+			if !p.acceptOperator(",") {
+				break
+			}
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expectOperator(")"); err != nil {
+		return nil, err
+	}
+	return cast, nil
+}
+
+// Parses an ODBC escape sequence, i.e. {fn ...}, {d '...'} or {ts '...'}, the
+// opening brace having been consumed already
+func (p *parser) parseEscapeSequence() (expr, error) {
+	if p.isKeyword("d") || p.isKeyword("ts") {
+		return p.parseDateTimeLiteral(p.next())
+	}
+	if !p.acceptKeyword("fn") {
+		return nil, p.expected("escape sequence")
+	}
+
+	name := p.peek()
+	if name.kind != tokenIdent {
+		return nil, p.expected("function name")
+	}
+	p.next()
+	if err := p.expectOperator("("); err != nil {
+		return nil, err
+	}
+	call, err := p.parseCall(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expectOperator("}"); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// Parses the string of a {d '...'} or {ts '...'} escape sequence, which must
+// be a date like 2023-05-01 or a timestamp like 2023-05-01 12:30:00
+func (p *parser) parseDateTimeLiteral(kind token) (expr, error) {
+	tok := p.peek()
+	if tok.kind != tokenString {
+		return nil, p.expected("string")
+	}
+	p.next()
+
+	var value any
+	if strings.EqualFold(kind.text, "d") {
+		t, err := time.Parse(dateFormat, tok.text)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid date '%s'", tok.text)
+		}
+		value = newDate(t)
+	} else {
+		t, ok := parseTime(tok.text, timestampLayouts)
+		if !ok {
+			return nil, p.errorf(tok, "invalid timestamp '%s'", tok.text)
+		}
+		value = newTimestamp(t)
+	}
+
+	if err := p.expectOperator("}"); err != nil {
+		return nil, err
+	}
+	return &literal{pos: kind.pos, value: value}, nil
+}
+
+func (p *parser) parseOperand() (expr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("||") {
+		tok := p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{pos: tok.pos, op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+// Qualified column names such as r.IPN are lexed as a single identifier, but
+// not when any part is quoted, as in r."parameter.Package"
+func (p *parser) parseQualifiedName(tok token) string {
+	name := tok.text
+	end := tok.end
+	for {
+		dot, next := p.peek(), p.tokens[min(p.current+1, len(p.tokens)-1)]
+		if dot.kind != tokenOperator || dot.text != "." || dot.pos != end || next.pos != dot.end || (next.kind != tokenIdent && next.kind != tokenQuotedIdent) {
+			return name
+		}
+		p.next()
+		p.next()
+		name += "." + next.text
+		end = next.end
+	}
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	start := p.current
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenNumber:
+		return &literal{pos: tok.pos, value: json.Number(tok.text)}, nil
+	case tokenString:
+		return &literal{pos: tok.pos, value: tok.text}, nil
+	case tokenParam:
+		p.numParams += 1
+		return &paramRef{pos: tok.pos, index: p.numParams - 1}, nil
+	case tokenQuotedIdent:
+		return &columnRef{pos: tok.pos, name: p.parseQualifiedName(tok)}, nil
+	case tokenIdent:
+		switch strings.ToUpper(tok.text) {
+		case "NULL":
+			return &literal{pos: tok.pos, value: nil}, nil
+		case "TRUE":
+			return &literal{pos: tok.pos, value: true}, nil
+		case "FALSE":
+			return &literal{pos: tok.pos, value: false}, nil
+		}
+		if reservedWords[strings.ToUpper(tok.text)] {
+			break
+		}
+		if p.acceptOperator("(") {
+			return p.parseCall(tok)
+		}
+		return &columnRef{pos: tok.pos, name: p.parseQualifiedName(tok)}, nil
+	case tokenOperator:
+		switch tok.text {
+		case "{":
+			return p.parseEscapeSequence()
+		case "(":
+			inner, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOperator(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "-", "+":
+			if number := p.peek(); number.kind == tokenNumber && number.pos == tok.end {
+				p.next()
+				text := number.text
+				if tok.text == "-" {
+					text = "-" + text
+				}
+				return &literal{pos: tok.pos, value: json.Number(text)}, nil
+			}
+			operand, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			return &unaryExpr{pos: tok.pos, op: tok.text, operand: operand}, nil
+		}
+	}
+
+	p.current = start
+	return nil, p.expected("expression")
+}
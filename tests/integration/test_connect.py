@@ -42,10 +42,23 @@ def test_connect_no_server(driver_name, port):
             f"Driver={driver_name};server=http://{hostname}:{portnumber};apitoken=asdf;httptimeout=1ms"
         )
 
-    assert exception.value.args[0] == "08001"
+    # Refused, unless it timed out first
+    assert exception.value.args[0] in ("08S01", "HYT00")
     assert "Error updating category list" in exception.value.args[1]
 
 
+@pytest.mark.parametrize("page_size", ["0", "-1", "many"])
+def test_connect_invalid_page_size(driver_name, httpserver, page_size):
+    server = httpserver.url_for("")
+    with pytest.raises(pypyodbc.DatabaseError) as exception:
+        pypyodbc.connect(
+            f"Driver={driver_name};server={server};apitoken=asdf;pagesize={page_size}"
+        )
+
+    assert exception.value.args[0] == "08001"
+    assert "pageSize" in exception.value.args[1]
+
+
 def test_connect_log(driver_name, tmp_path):
     logfile = tmp_path / "logfile.log"
     with pytest.raises(pypyodbc.DatabaseError) as exception:
@@ -66,5 +79,31 @@ def test_invalid_credentials(driver_name, httpserver):
             f"Driver={driver_name};server={server};username=asdf;password=asdf"
         )
 
-    assert exception.value.args[0] == "08001"
+    assert exception.value.args[0] == "28000"
     assert "401" in exception.value.args[1]
+
+
+def test_invalid_credentials_detail(driver_name, httpserver):
+    server = httpserver.url_for("")
+    httpserver.expect_request("/api/user/token").respond_with_json(
+        {"detail": "Invalid username/password."}, status=401
+    )
+    with pytest.raises(pypyodbc.DatabaseError) as exception:
+        pypyodbc.connect(
+            f"Driver={driver_name};server={server};username=asdf;password=asdf"
+        )
+
+    assert exception.value.args[0] == "28000"
+    assert "Invalid username/password." in exception.value.args[1]
+
+
+def test_connect_server_error(driver_name, httpserver):
+    server = httpserver.url_for("")
+    httpserver.expect_request("/api/part/category/").respond_with_json(
+        {"error": "Database is unavailable"}, status=503
+    )
+    with pytest.raises(pypyodbc.DatabaseError) as exception:
+        pypyodbc.connect(f"Driver={driver_name};server={server};apitoken=asdf")
+
+    assert exception.value.args[0] == "08S01"
+    assert "Database is unavailable" in exception.value.args[1]
@@ -1,4 +1,6 @@
 import copy
+import ctypes
+import datetime
 import json
 import platform
 import sys
@@ -342,6 +344,29 @@ def part_parameters_resource(httpserver):
         )
 
 
+@pytest.fixture
+def category_parameters_resource(httpserver):
+    httpserver.expect_request(
+        f"/api/part/parameter/", query_string="category=59"
+    ).respond_with_json(
+        [
+            {
+                "pk": pk,
+                "part": part["pk"],
+                "template": 3,
+                "template_detail": {
+                    "pk": 3,
+                    "name": "Package",
+                    "units": "",
+                    "description": "",
+                },
+                "data": "0805",
+            }
+            for pk, part in enumerate(parts)
+        ]
+    )
+
+
 def test_unconditional_select_invalid_table(
     httpserver, driver_name, token_resource, categories_resource
 ):
@@ -350,27 +375,1706 @@ def test_unconditional_select_invalid_table(
         f"Driver={driver_name};server={server};username=asdf;password=asdf"
     )
     crsr = cnxn.cursor()
-    crsr.prepare("SELECT * FROM Pizzas")
-    # pypyodbc doesn't allow us to execute the prepared statements
+    crsr.prepare("SELECT * FROM Pizzas")
+    # pypyodbc doesn't allow us to execute the prepared statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    assert ret == pypyodbc.SQL_ERROR
+    with pytest.raises(pypyodbc.Error) as exception:
+        # Because SQLExecute was updated directly, also call:
+        pypyodbc.check_success(crsr, ret)
+    assert "Unable to fetch parts" in exception.value.args[1]
+    assert "Category does not exist" in exception.value.args[1]
+
+
+def test_unconditional_select_resource_error(
+    httpserver, driver_name, token_resource, categories_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare("SELECT * FROM Resistors")
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    assert ret == pypyodbc.SQL_ERROR
+    with pytest.raises(pypyodbc.Error) as exception:
+        # Because SQLExecute was updated directly, also call:
+        pypyodbc.check_success(crsr, ret)
+    assert "Unable to fetch parts" in exception.value.args[1]
+
+
+@pytest.mark.parametrize(
+    "query",
+    [
+        "SELECT * FROM Resistors",
+        "SELECT IPN FROM Resistors",
+        'SELECT "IPN" FROM Resistors',
+        "SELECT IPN, pk FROM Resistors",
+        'SELECT "IPN", "pk" FROM Resistors',
+        "SELECT IPN,pk FROM Resistors",
+        'SELECT "IPN","pk" FROM Resistors',
+        'SELECT IPN AS "Part Number" FROM "Resistors";',
+        "select ipn, pk from Resistors -- trailing comment",
+    ],
+)
+def test_unconditional_select(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    category_parameters_resource,
+    query,
+):
+    # TODO: check the category in query string for parts request
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(query)
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+    assert (
+        ret == pypyodbc.SQL_SUCCESS
+    )  # redundant, but what we actually are trying to do
+    # Because SQLExecute was updated directly, also call:
+    pypyodbc.check_success(crsr, ret)
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    results = crsr.fetchall()
+    assert len(results) == 4
+
+
+@pytest.mark.parametrize(
+    "query, expected_columns, expected",
+    [
+        (
+            "SELECT IPN, pk AS Key FROM Resistors WHERE pk = 37",
+            ["ipn", "key"],
+            [("RES-000037-00", 37)],
+        ),
+        (
+            'SELECT "parameter.Package", name FROM Resistors WHERE pk IN (16, 18)',
+            ["parameter.package", "name"],
+            [
+                ("0805", "0R resistor 0% SMD 0805"),
+                ("0805", "100nF Ceramic Capacitor 50V 10% 0805"),
+            ],
+        ),
+        (
+            "SELECT pk, parameter.Nope AS nope, in_stock > 100 AS many FROM Resistors",
+            ["pk", "nope", "many"],
+            [(16, None, 0), (37, None, 0), (18, None, 1), (30, None, 0)],
+        ),
+    ],
+)
+def test_select_columns(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    part_resource,
+    category_parameters_resource,
+    query,
+    expected_columns,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(query)
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    assert [column[0] for column in crsr.description] == expected_columns
+    assert [tuple(row) for row in crsr.fetchall()] == expected
+
+
+@pytest.mark.parametrize(
+    "query, expected",
+    [
+        ("SELECT IPN, name FROM Resistors", ["/api/part/"]),
+        ("SELECT IPN FROM Resistors WHERE pk = 16", ["/api/part/16/"]),
+        (
+            "SELECT IPN FROM Resistors WHERE parameter.Package = '0805'",
+            ["/api/part/", "/api/part/parameter/"],
+        ),
+        (
+            "SELECT IPN FROM Resistors WHERE pk = 16 AND parameter.Package = '0805'",
+            ["/api/part/16/", "/api/part/parameter/"],
+        ),
+        # Only the parameters of the parts at hand rather than the category's
+        (
+            "SELECT IPN FROM Resistors WHERE pk IN (16, 37) AND parameter.Package = '0805'",
+            ["/api/part/", "/api/part/parameter/", "/api/part/parameter/"],
+        ),
+        (
+            "SELECT IPN, parameter.Package FROM Resistors LIMIT 2",
+            ["/api/part/", "/api/part/parameter/", "/api/part/parameter/"],
+        ),
+    ],
+)
+def test_select_columns_requests(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    part_resource,
+    part_parameters_resource,
+    category_parameters_resource,
+    query,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(query)
+    httpserver.clear_log()
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+
+    assert sorted(request.path for request, _ in httpserver.log) == expected
+
+
+@pytest.fixture
+def part_options_resource(httpserver):
+    # Must come before parts_resource, which answers requests of any method
+    httpserver.expect_request("/api/part/", method="OPTIONS").respond_with_json(
+        {
+            "name": "Part List",
+            "actions": {
+                "POST": {
+                    "pk": {"type": "integer", "required": False, "read_only": True},
+                    "IPN": {
+                        "type": "string",
+                        "required": False,
+                        "read_only": False,
+                        "allow_null": True,
+                        "max_length": 100,
+                    },
+                    "name": {
+                        "type": "string",
+                        "required": True,
+                        "read_only": False,
+                        "max_length": 100,
+                    },
+                    "in_stock": {"type": "float", "required": False, "read_only": True},
+                    "active": {
+                        "type": "boolean",
+                        "required": False,
+                        "read_only": False,
+                    },
+                    "creation_date": {
+                        "type": "date",
+                        "required": False,
+                        "read_only": True,
+                    },
+                    "tags": {"type": "list", "required": False, "read_only": False},
+                    "duplicate": {
+                        "type": "nested object",
+                        "required": False,
+                        "read_only": False,
+                        "write_only": True,
+                    },
+                }
+            },
+        }
+    )
+
+
+@pytest.fixture
+def category_parameter_templates_resource(httpserver):
+    httpserver.expect_request(
+        "/api/part/category/parameters/", query_string="category=59"
+    ).respond_with_json(
+        [
+            {
+                "pk": 1,
+                "category": 59,
+                "parameter_template": 3,
+                "parameter_template_detail": {"pk": 3, "name": "Package", "units": ""},
+                "default_value": "",
+            },
+            {
+                "pk": 2,
+                "category": 59,
+                "parameter_template": 7,
+                "parameter_template_detail": {"pk": 7, "name": "Power", "units": "W"},
+                "default_value": "",
+            },
+        ]
+    )
+
+
+@pytest.mark.parametrize(
+    "query",
+    [
+        "SELECT * FROM Resistors",
+        "SELECT * FROM Resistors WHERE pk = 16",
+        "SELECT * FROM Resistors WHERE IPN = 'nope'",
+    ],
+)
+def test_select_schema(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    part_options_resource,
+    category_parameter_templates_resource,
+    parts_resource,
+    part_resource,
+    category_parameters_resource,
+    query,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(query)
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    # The columns described by the schema don't depend on the rows returned
+    # (type code, size, nullable) of the columns
+    columns = {
+        column[0]: (column[1], column[3], column[6]) for column in crsr.description
+    }
+    assert columns["pk"] == (int, 20, False)
+    assert columns["ipn"] == (str, 100, True)
+    assert columns["name"] == (str, 100, False)
+    assert columns["in_stock"][0] == float
+    assert columns["active"][0] == int
+    assert columns["creation_date"][0] == datetime.date
+    assert columns["parameter.package"][0] == str
+    assert columns["parameter.power"][0] == str
+    assert "tags" not in columns
+    assert "duplicate" not in columns
+
+
+def test_exec_direct(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    # Without parameters pypyodbc executes the statement using SQLExecDirect
+    crsr.execute("SELECT pk, IPN FROM Resistors WHERE pk IN (16, 37)")
+
+    assert [tuple(row) for row in crsr.fetchall()] == [
+        (16, "RES-000014-00"),
+        (37, "RES-000037-00"),
+    ]
+
+
+@pytest.mark.part_mods(
+    [
+        ("/0/name", "100\u00b5F \u00b120%"),
+        ("/1/name", "4k7\u03a9 \U0001f600"),
+    ]
+)
+def test_unicode(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    # pypyodbc uses the wide entry points for str statements
+    crsr.execute(
+        "SELECT pk, name AS \"n\u00e4me\", '\u03a9' FROM Resistors "
+        "WHERE name LIKE '%\u00b5%' OR name LIKE '%\u03a9%'"
+    )
+
+    assert crsr.description[1][0] == "n\u00e4me"
+    assert [tuple(row) for row in crsr.fetchall()] == [
+        (16, "100\u00b5F \u00b120%", "\u03a9"),
+        (37, "4k7\u03a9 \U0001f600", "\u03a9"),
+    ]
+
+
+def test_parameters(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    query = "SELECT pk FROM Resistors WHERE IPN = ? OR pk = ? OR in_stock > ?"
+    # The statement is prepared once and then executed with each set of values
+    for params, expected in [
+        (["RES-000014-00", 18, 1000], [(16,), (18,)]),
+        (["CAP-000030-00", 37, 1000], [(37,), (30,)]),
+        ([None, None, 150], [(18,)]),
+    ]:
+        crsr.execute(query, params)
+        assert [tuple(row) for row in crsr.fetchall()] == expected
+
+
+SQL_ATTR_ROW_BIND_TYPE = 5
+SQL_ATTR_CURSOR_TYPE = 6
+SQL_ATTR_PARAM_STATUS_PTR = 20
+SQL_ATTR_PARAMS_PROCESSED_PTR = 21
+SQL_ATTR_PARAMSET_SIZE = 22
+SQL_ATTR_ROW_BIND_OFFSET_PTR = 23
+SQL_ATTR_ROW_STATUS_PTR = 25
+SQL_ATTR_ROWS_FETCHED_PTR = 26
+SQL_ATTR_ROW_ARRAY_SIZE = 27
+SQL_PARAM_INPUT = 1
+SQL_C_CHAR = 1
+SQL_C_WCHAR = -8
+SQL_C_BIT = -7
+SQL_C_FLOAT = 7
+SQL_C_DOUBLE = 8
+SQL_C_SSHORT = -15
+SQL_C_SLONG = -16
+SQL_C_SBIGINT = -25
+SQL_C_UTINYINT = -28
+SQL_C_TYPE_DATE = 91
+SQL_C_TYPE_TIMESTAMP = 93
+SQL_C_DEFAULT = 99
+SQL_ROW_SUCCESS = 0
+SQL_ROW_NOROW = 3
+SQL_NO_DATA = 100
+SQL_CURSOR_STATIC = 3
+SQL_FETCH_NEXT = 1
+SQL_FETCH_FIRST = 2
+SQL_FETCH_LAST = 3
+SQL_FETCH_PRIOR = 4
+SQL_FETCH_ABSOLUTE = 5
+SQL_FETCH_RELATIVE = 6
+SQL_INTEGER = 4
+SQL_VARCHAR = 12
+SQL_NTS = -3
+
+
+def test_parameter_array(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare("SELECT ?, pk FROM Resistors WHERE IPN = ?")
+
+    # pypyodbc has no support for parameter arrays, so bind them directly
+    api = pypyodbc.ODBC_API
+    tags = (ctypes.c_int * 3)(1, 2, 3)
+    ipns = (ctypes.c_char * 20 * 3)()
+    for buffer, ipn in zip(
+        ipns, [b"RES-000014-00", b"CAP-000030-00", b"RES-000037-00"]
+    ):
+        buffer.value = ipn
+    indicators = (ctypes.c_ssize_t * 3)(SQL_NTS, SQL_NTS, SQL_NTS)
+    processed = ctypes.c_size_t()
+    statuses = (ctypes.c_ushort * 3)()
+    for attribute, value in [
+        (SQL_ATTR_PARAMSET_SIZE, ctypes.c_void_p(3)),
+        (
+            SQL_ATTR_PARAMS_PROCESSED_PTR,
+            ctypes.cast(ctypes.byref(processed), ctypes.c_void_p),
+        ),
+        (SQL_ATTR_PARAM_STATUS_PTR, ctypes.cast(statuses, ctypes.c_void_p)),
+    ]:
+        ret = api.SQLSetStmtAttr(crsr.stmt_h, attribute, value, 0)
+        assert ret == pypyodbc.SQL_SUCCESS
+    for number, value_type, parameter_type, value, length, indicator in [
+        (1, SQL_C_SLONG, SQL_INTEGER, tags, 0, None),
+        (2, SQL_C_CHAR, SQL_VARCHAR, ipns, 20, indicators),
+    ]:
+        ret = api.SQLBindParameter(
+            crsr.stmt_h,
+            number,
+            SQL_PARAM_INPUT,
+            value_type,
+            parameter_type,
+            0,
+            0,
+            ctypes.cast(value, ctypes.c_void_p),
+            length,
+            None
+            if indicator is None
+            else ctypes.cast(indicator, ctypes.POINTER(ctypes.c_ssize_t)),
+        )
+        assert ret == pypyodbc.SQL_SUCCESS
+
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    assert [tuple(row) for row in crsr.fetchall()] == [(1, 16), (2, 30), (3, 37)]
+    assert processed.value == 3
+    assert list(statuses) == [0, 0, 0]
+
+
+SQL_PARAM_SUCCESS_WITH_INFO = 6
+
+
+def test_parameter_array_warning(
+    httpserver, driver_name, token_resource, categories_resource, part_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare("SELECT pk, parameter.Package FROM Resistors WHERE pk = ?")
+
+    api = pypyodbc.ODBC_API
+    pks = (ctypes.c_int * 2)(16, 37)
+    statuses = (ctypes.c_ushort * 2)()
+    for attribute, value in [
+        (SQL_ATTR_PARAMSET_SIZE, ctypes.c_void_p(2)),
+        (SQL_ATTR_PARAM_STATUS_PTR, ctypes.cast(statuses, ctypes.c_void_p)),
+    ]:
+        ret = api.SQLSetStmtAttr(crsr.stmt_h, attribute, value, 0)
+        assert ret == pypyodbc.SQL_SUCCESS
+    ret = api.SQLBindParameter(
+        crsr.stmt_h,
+        1,
+        SQL_PARAM_INPUT,
+        SQL_C_SLONG,
+        SQL_INTEGER,
+        0,
+        0,
+        ctypes.cast(pks, ctypes.c_void_p),
+        0,
+        None,
+    )
+    assert ret == pypyodbc.SQL_SUCCESS
+
+    # Without a resource for the parameters, each set has a warning
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    assert ret == pypyodbc.SQL_SUCCESS_WITH_INFO
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    assert [tuple(row) for row in crsr.fetchall()] == [(16, None), (37, None)]
+    assert list(statuses) == [SQL_PARAM_SUCCESS_WITH_INFO] * 2
+
+
+def test_long_text(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    # Longer than the size the column is described with, so that it has to be
+    # retrieved in several chunks
+    text = "0123456789" * 100
+    crsr.execute(f"SELECT '{text}', pk FROM Resistors WHERE pk = 16")
+    assert [tuple(row) for row in crsr.fetchall()] == [(text, 16)]
+
+
+SQL_DESC_NAME = 1011
+
+
+def test_col_attribute_length(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.execute("SELECT pk, name FROM Resistors")
+    length = ctypes.c_short()
+    # Asking for just the length isn't truncating the string
+    ret = pypyodbc.ODBC_API.SQLColAttribute(
+        crsr.stmt_h, 2, SQL_DESC_NAME, None, 0, ctypes.byref(length), None
+    )
+    assert ret == pypyodbc.SQL_SUCCESS
+    assert length.value == len("name")
+
+
+def get_data_chunks(crsr, column, target_type, buffer_length):
+    api = pypyodbc.ODBC_API
+    buffer = ctypes.create_string_buffer(buffer_length)
+    indicator = ctypes.c_ssize_t()
+    chunks = []
+    while True:
+        ret = api.SQLGetData(
+            crsr.stmt_h,
+            column,
+            target_type,
+            ctypes.cast(buffer, ctypes.c_void_p),
+            buffer_length,
+            ctypes.byref(indicator),
+        )
+        if ret == SQL_NO_DATA:
+            return chunks
+        assert ret in [pypyodbc.SQL_SUCCESS, pypyodbc.SQL_SUCCESS_WITH_INFO]
+        if target_type == SQL_C_WCHAR:
+            data = buffer.raw[: min(indicator.value, buffer_length - 2)]
+            chunks.append((ret, indicator.value, data.decode("utf-16-le")))
+        else:
+            data = buffer.raw[: min(indicator.value, buffer_length - 1)]
+            chunks.append((ret, indicator.value, data.decode("utf-8")))
+
+
+def test_get_data_chunks(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+
+    # Pass the non-ASCII text as a parameter, as the driver manager may not
+    # convert it in the text of the statement
+    api = pypyodbc.ODBC_API
+    cheese = ctypes.create_string_buffer("Käse 🧀".encode("utf-16-le") + b"\0\0")
+    ret = api.SQLBindParameter(
+        crsr.stmt_h,
+        1,
+        SQL_PARAM_INPUT,
+        SQL_C_WCHAR,
+        SQL_VARCHAR,
+        0,
+        0,
+        ctypes.cast(cheese, ctypes.c_void_p),
+        len(cheese),
+        None,
+    )
+    assert ret == pypyodbc.SQL_SUCCESS
+    query = b"SELECT 'abcdefghij', ?, pk FROM Resistors WHERE pk = 16"
+    assert api.SQLExecDirect(crsr.stmt_h, query, len(query)) == pypyodbc.SQL_SUCCESS
+    assert api.SQLFetch(crsr.stmt_h) == pypyodbc.SQL_SUCCESS
+
+    info = pypyodbc.SQL_SUCCESS_WITH_INFO
+    assert get_data_chunks(crsr, 1, SQL_C_CHAR, 5) == [
+        (info, 10, "abcd"),
+        (info, 6, "efgh"),
+        (pypyodbc.SQL_SUCCESS, 2, "ij"),
+    ]
+    # Lengths are in bytes and surrogate pairs are kept together
+    assert get_data_chunks(crsr, 2, SQL_C_WCHAR, 12) == [
+        (info, 14, "Käse "),
+        (pypyodbc.SQL_SUCCESS, 4, "🧀"),
+    ]
+    assert get_data_chunks(crsr, 3, SQL_C_CHAR, 10) == [
+        (pypyodbc.SQL_SUCCESS, 2, "16")
+    ]
+
+
+class DateStruct(ctypes.Structure):
+    _fields_ = [
+        ("year", ctypes.c_short),
+        ("month", ctypes.c_ushort),
+        ("day", ctypes.c_ushort),
+    ]
+
+
+class TimestampStruct(ctypes.Structure):
+    _fields_ = [
+        ("year", ctypes.c_short),
+        ("month", ctypes.c_ushort),
+        ("day", ctypes.c_ushort),
+        ("hour", ctypes.c_ushort),
+        ("minute", ctypes.c_ushort),
+        ("second", ctypes.c_ushort),
+        ("fraction", ctypes.c_uint),
+    ]
+
+
+@pytest.mark.parametrize(
+    "column, target_type, buffer_type, ret, expected",
+    [
+        ("pk", SQL_C_DOUBLE, ctypes.c_double, pypyodbc.SQL_SUCCESS, 16.0),
+        ("pk", SQL_C_FLOAT, ctypes.c_float, pypyodbc.SQL_SUCCESS, 16.0),
+        ("pk", SQL_C_SSHORT, ctypes.c_short, pypyodbc.SQL_SUCCESS, 16),
+        ("pk", SQL_C_UTINYINT, ctypes.c_ubyte, pypyodbc.SQL_SUCCESS, 16),
+        ("pk", SQL_C_DEFAULT, ctypes.c_int64, pypyodbc.SQL_SUCCESS, 16),
+        ("pk", SQL_C_BIT, ctypes.c_ubyte, pypyodbc.SQL_ERROR, "22003"),
+        ("pk = 16", SQL_C_BIT, ctypes.c_ubyte, pypyodbc.SQL_SUCCESS, 1),
+        ("pk = 16", SQL_C_SLONG, ctypes.c_int, pypyodbc.SQL_SUCCESS, 1),
+        ("' 42 '", SQL_C_SLONG, ctypes.c_int, pypyodbc.SQL_SUCCESS, 42),
+        ("'12.5'", SQL_C_DOUBLE, ctypes.c_double, pypyodbc.SQL_SUCCESS, 12.5),
+        ("'12.5'", SQL_C_SLONG, ctypes.c_int, pypyodbc.SQL_SUCCESS_WITH_INFO, 12),
+        ("'-300'", SQL_C_SSHORT, ctypes.c_short, pypyodbc.SQL_SUCCESS, -300),
+        ("'-300'", SQL_C_UTINYINT, ctypes.c_ubyte, pypyodbc.SQL_ERROR, "22003"),
+        ("'abc'", SQL_C_SLONG, ctypes.c_int, pypyodbc.SQL_ERROR, "22018"),
+        ("'abc'", SQL_C_DOUBLE, ctypes.c_double, pypyodbc.SQL_ERROR, "22018"),
+        (
+            "'2023-06-08'",
+            SQL_C_TYPE_DATE,
+            DateStruct,
+            pypyodbc.SQL_SUCCESS,
+            (2023, 6, 8),
+        ),
+        (
+            "'2023-06-08 09:51:12.5'",
+            SQL_C_TYPE_TIMESTAMP,
+            TimestampStruct,
+            pypyodbc.SQL_SUCCESS,
+            (2023, 6, 8, 9, 51, 12, 500000000),
+        ),
+        ("'tomorrow'", SQL_C_TYPE_DATE, DateStruct, pypyodbc.SQL_ERROR, "22018"),
+        ("pk", SQL_C_TYPE_DATE, DateStruct, pypyodbc.SQL_ERROR, "07006"),
+        (
+            "{d '2023-06-08'}",
+            SQL_C_DEFAULT,
+            DateStruct,
+            pypyodbc.SQL_SUCCESS,
+            (2023, 6, 8),
+        ),
+        (
+            "{ts '2023-06-08 09:51:12'}",
+            SQL_C_TYPE_DATE,
+            DateStruct,
+            pypyodbc.SQL_SUCCESS_WITH_INFO,
+            (2023, 6, 8),
+        ),
+        ("{d '2023-06-08'}", SQL_C_SLONG, ctypes.c_int, pypyodbc.SQL_ERROR, "07006"),
+    ],
+)
+def test_get_data_conversion(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    column,
+    target_type,
+    buffer_type,
+    ret,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.execute(f"SELECT {column} FROM Resistors WHERE pk = 16")
+
+    api = pypyodbc.ODBC_API
+    assert api.SQLFetch(crsr.stmt_h) == pypyodbc.SQL_SUCCESS
+    buffer = buffer_type()
+    indicator = ctypes.c_ssize_t()
+    result = api.SQLGetData(
+        crsr.stmt_h,
+        1,
+        target_type,
+        ctypes.byref(buffer),
+        ctypes.sizeof(buffer),
+        ctypes.byref(indicator),
+    )
+    assert result == ret
+    if ret == pypyodbc.SQL_ERROR:
+        with pytest.raises(pypyodbc.Error) as exception:
+            pypyodbc.check_success(crsr, result)
+        assert exception.value.args[0] == expected
+    elif isinstance(buffer, ctypes.Structure):
+        assert tuple(getattr(buffer, name) for name, _ in buffer._fields_) == expected
+        assert indicator.value == ctypes.sizeof(buffer)
+    else:
+        assert buffer.value == expected
+        assert indicator.value == ctypes.sizeof(buffer)
+
+
+def set_stmt_attrs(crsr, attrs):
+    for attribute, value in attrs:
+        ret = pypyodbc.ODBC_API.SQLSetStmtAttr(crsr.stmt_h, attribute, value, 0)
+        assert ret == pypyodbc.SQL_SUCCESS
+
+
+def test_block_cursor_column_wise(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.execute("SELECT pk, IPN FROM Resistors ORDER BY pk")
+
+    # pypyodbc fetches a row at a time, so bind and fetch rowsets directly
+    api = pypyodbc.ODBC_API
+    pks = (ctypes.c_int64 * 3)()
+    pk_indicators = (ctypes.c_ssize_t * 3)()
+    ipns = (ctypes.c_char * 20 * 3)()
+    ipn_indicators = (ctypes.c_ssize_t * 3)()
+    fetched = ctypes.c_size_t()
+    statuses = (ctypes.c_ushort * 3)()
+    set_stmt_attrs(
+        crsr,
+        [
+            (SQL_ATTR_ROW_ARRAY_SIZE, ctypes.c_void_p(3)),
+            (
+                SQL_ATTR_ROWS_FETCHED_PTR,
+                ctypes.cast(ctypes.byref(fetched), ctypes.c_void_p),
+            ),
+            (SQL_ATTR_ROW_STATUS_PTR, ctypes.cast(statuses, ctypes.c_void_p)),
+        ],
+    )
+    for number, target_type, value, length, indicators in [
+        (1, SQL_C_SBIGINT, pks, 0, pk_indicators),
+        (2, SQL_C_CHAR, ipns, 20, ipn_indicators),
+    ]:
+        ret = api.SQLBindCol(
+            crsr.stmt_h,
+            number,
+            target_type,
+            ctypes.cast(value, ctypes.c_void_p),
+            length,
+            ctypes.cast(indicators, ctypes.POINTER(ctypes.c_ssize_t)),
+        )
+        assert ret == pypyodbc.SQL_SUCCESS
+
+    assert api.SQLFetch(crsr.stmt_h) == pypyodbc.SQL_SUCCESS
+    assert fetched.value == 3
+    assert list(statuses) == [SQL_ROW_SUCCESS] * 3
+    assert list(pks) == [16, 18, 30]
+    assert [ipn.value for ipn in ipns] == [
+        b"RES-000014-00",
+        b"CAP-000015-00",
+        b"CAP-000030-00",
+    ]
+    assert list(ipn_indicators) == [13, 13, 13]
+
+    # The last rowset is only partially filled
+    assert api.SQLFetch(crsr.stmt_h) == pypyodbc.SQL_SUCCESS
+    assert fetched.value == 1
+    assert list(statuses) == [SQL_ROW_SUCCESS, SQL_ROW_NOROW, SQL_ROW_NOROW]
+    assert pks[0] == 37
+    assert ipns[0].value == b"RES-000037-00"
+
+    assert api.SQLFetch(crsr.stmt_h) == SQL_NO_DATA
+    assert fetched.value == 0
+
+
+def test_scrollable_cursor(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    set_stmt_attrs(crsr, [(SQL_ATTR_CURSOR_TYPE, ctypes.c_void_p(SQL_CURSOR_STATIC))])
+    crsr.execute("SELECT pk FROM Resistors ORDER BY pk")
+
+    api = pypyodbc.ODBC_API
+    pks = (ctypes.c_int64 * 2)()
+    indicators = (ctypes.c_ssize_t * 2)()
+    fetched = ctypes.c_size_t()
+    set_stmt_attrs(
+        crsr,
+        [
+            (SQL_ATTR_ROW_ARRAY_SIZE, ctypes.c_void_p(2)),
+            (
+                SQL_ATTR_ROWS_FETCHED_PTR,
+                ctypes.cast(ctypes.byref(fetched), ctypes.c_void_p),
+            ),
+        ],
+    )
+    ret = api.SQLBindCol(
+        crsr.stmt_h,
+        1,
+        SQL_C_SBIGINT,
+        ctypes.cast(pks, ctypes.c_void_p),
+        0,
+        ctypes.cast(indicators, ctypes.POINTER(ctypes.c_ssize_t)),
+    )
+    assert ret == pypyodbc.SQL_SUCCESS
+
+    # The rows are 16, 18, 30 and 37, fetched two at a time
+    for orientation, offset, ret, expected in [
+        (SQL_FETCH_LAST, 0, pypyodbc.SQL_SUCCESS, [30, 37]),
+        (SQL_FETCH_PRIOR, 0, pypyodbc.SQL_SUCCESS, [16, 18]),
+        (SQL_FETCH_PRIOR, 0, SQL_NO_DATA, []),
+        (SQL_FETCH_NEXT, 0, pypyodbc.SQL_SUCCESS, [16, 18]),
+        (SQL_FETCH_ABSOLUTE, -1, pypyodbc.SQL_SUCCESS, [37]),
+        (SQL_FETCH_RELATIVE, -2, pypyodbc.SQL_SUCCESS, [18, 30]),
+        (SQL_FETCH_ABSOLUTE, 3, pypyodbc.SQL_SUCCESS, [30, 37]),
+        (SQL_FETCH_RELATIVE, 2, SQL_NO_DATA, []),
+        (SQL_FETCH_FIRST, 0, pypyodbc.SQL_SUCCESS, [16, 18]),
+        (SQL_FETCH_RELATIVE, -1, SQL_NO_DATA, []),
+        (SQL_FETCH_ABSOLUTE, 2, pypyodbc.SQL_SUCCESS, [18, 30]),
+        # A rowset can't start before the first row, so this one starts there
+        (SQL_FETCH_PRIOR, 0, pypyodbc.SQL_SUCCESS_WITH_INFO, [16, 18]),
+    ]:
+        assert api.SQLFetchScroll(crsr.stmt_h, orientation, offset) == ret
+        assert list(pks[: fetched.value]) == expected
+
+
+def test_forward_only_cursor(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.execute("SELECT pk FROM Resistors ORDER BY pk")
+
+    ret = pypyodbc.ODBC_API.SQLFetchScroll(crsr.stmt_h, SQL_FETCH_LAST, 0)
+    assert ret == pypyodbc.SQL_ERROR
+    with pytest.raises(pypyodbc.Error) as exception:
+        pypyodbc.check_success(crsr, ret)
+    assert exception.value.args[0] == "HY106"
+
+
+class PartRow(ctypes.Structure):
+    _fields_ = [
+        ("pk", ctypes.c_int64),
+        ("pk_indicator", ctypes.c_ssize_t),
+        ("ipn", ctypes.c_char * 20),
+        ("ipn_indicator", ctypes.c_ssize_t),
+    ]
+
+
+def test_block_cursor_row_wise(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.execute("SELECT pk, IPN FROM Resistors ORDER BY pk DESC")
+
+    # Columns are bound to the first row and the offset moves them to the second
+    api = pypyodbc.ODBC_API
+    rows = (PartRow * 5)()
+    offset = ctypes.c_size_t(ctypes.sizeof(PartRow))
+    fetched = ctypes.c_size_t()
+    set_stmt_attrs(
+        crsr,
+        [
+            (SQL_ATTR_ROW_ARRAY_SIZE, ctypes.c_void_p(4)),
+            (SQL_ATTR_ROW_BIND_TYPE, ctypes.c_void_p(ctypes.sizeof(PartRow))),
+            (
+                SQL_ATTR_ROW_BIND_OFFSET_PTR,
+                ctypes.cast(ctypes.byref(offset), ctypes.c_void_p),
+            ),
+            (
+                SQL_ATTR_ROWS_FETCHED_PTR,
+                ctypes.cast(ctypes.byref(fetched), ctypes.c_void_p),
+            ),
+        ],
+    )
+    for number, target_type, field, length, indicator in [
+        (1, SQL_C_SBIGINT, "pk", 0, "pk_indicator"),
+        (2, SQL_C_CHAR, "ipn", 20, "ipn_indicator"),
+    ]:
+        ret = api.SQLBindCol(
+            crsr.stmt_h,
+            number,
+            target_type,
+            ctypes.c_void_p(
+                ctypes.addressof(rows[0]) + getattr(PartRow, field).offset
+            ),
+            length,
+            ctypes.cast(
+                ctypes.addressof(rows[0]) + getattr(PartRow, indicator).offset,
+                ctypes.POINTER(ctypes.c_ssize_t),
+            ),
+        )
+        assert ret == pypyodbc.SQL_SUCCESS
+
+    assert api.SQLFetch(crsr.stmt_h) == pypyodbc.SQL_SUCCESS
+    assert fetched.value == 4
+    assert [(row.pk, row.ipn) for row in rows] == [
+        (0, b""),
+        (37, b"RES-000037-00"),
+        (30, b"CAP-000030-00"),
+        (18, b"CAP-000015-00"),
+        (16, b"RES-000014-00"),
+    ]
+
+
+def test_exec_direct_syntax_error(
+    httpserver, driver_name, token_resource, categories_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    with pytest.raises(pypyodbc.Error) as exception:
+        crsr.execute("SELECT FROM Resistors")
+    assert exception.value.args[0] == "42000"
+    assert "syntax error at position 8" in exception.value.args[1]
+
+
+@pytest.mark.parametrize(
+    "query, expected",
+    [
+        (
+            "SELECT FROM Resistors",
+            "syntax error at position 8: expression expected, got 'FROM'",
+        ),
+        (
+            "SELECT * FROM Resistors WHERE",
+            "syntax error at position 30: expression expected, got end of statement",
+        ),
+        (
+            "SELECT * FROM Resistors WHERE IPN = 'RES",
+            "syntax error at position 37: unterminated string",
+        ),
+        (
+            "SELECT * FROM Resistors WHERE pk = 1 2",
+            "syntax error at position 38: unexpected '2'",
+        ),
+        (
+            "DELETE FROM Resistors",
+            "syntax error at position 1: SELECT expected, got 'DELETE'",
+        ),
+        (
+            "SELECT FOO(pk) FROM Resistors",
+            "syntax error at position 8: unknown function FOO",
+        ),
+        (
+            "SELECT {fn UCASE(pk, 1)} FROM Resistors",
+            "syntax error at position 12: UCASE takes 1 argument(s)",
+        ),
+        (
+            "SELECT CAST(pk AS BLOB) FROM Resistors",
+            "syntax error at position 19: data type expected, got 'BLOB'",
+        ),
+        (
+            "SELECT r.pk FROM Resistors r RIGHT JOIN Capacitors c ON r.pk = c.pk",
+            "syntax error at position 30: RIGHT JOIN is not supported",
+        ),
+        (
+            "SELECT pk FROM Resistors JOIN Resistors ON pk = pk",
+            "syntax error at position 31: duplicate table name Resistors, use an alias",
+        ),
+    ],
+)
+def test_prepare_syntax_error(
+    httpserver, driver_name, token_resource, categories_resource, query, expected
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    with pytest.raises(pypyodbc.Error) as exception:
+        crsr.prepare(query)
+    assert exception.value.args[0] == "42000"
+    assert expected in exception.value.args[1]
+
+
+@pytest.mark.parametrize(
+    "sql, state, message",
+    [
+        (
+            "SELECT * FROM Resistors WHERE qqq = 1",
+            "42S22",
+            "Column not found at position 31: qqq",
+        ),
+        (
+            "SELECT * FROM Resistors WHERE name",
+            "22018",
+            "Invalid boolean value at position 31: 0R resistor 0% SMD 0805",
+        ),
+    ],
+)
+def test_conditional_select_invalid_condition(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    category_parameters_resource,
+    sql,
+    state,
+    message,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(sql)
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    assert ret == pypyodbc.SQL_ERROR
+    with pytest.raises(pypyodbc.Error) as exception:
+        # Because SQLExecute was updated directly, also call:
+        pypyodbc.check_success(crsr, ret)
+    assert state == exception.value.args[0]
+    assert message in exception.value.args[1]
+
+
+@pytest.mark.parametrize(
+    "condition, expected",
+    [
+        ("in_stock > 100", [18]),
+        ("in_stock >= 100 AND NOT IPN = 'RES-000014-00'", [37, 18]),
+        ("pk = 16 OR revision = 'A'", [16, 30]),
+        ("ipn <> 'RES-000037-00' AND (in_stock < 100 OR pk = 18)", [18, 30]),
+        ("link IS NULL", [30]),
+        ("link IS NOT NULL AND active", [16, 37, 18]),
+        ("image = 'nope' OR NOT (in_stock <> 30)", [30]),
+        ("in_stock = '100'", [16, 37]),
+        ("NOT active", []),
+    ],
+)
+def test_conditional_select_expression(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    category_parameters_resource,
+    condition,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(f"SELECT * FROM Resistors WHERE {condition}")
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    pk = [column[0] for column in crsr.description].index("pk")
+    assert [row[pk] for row in crsr.fetchall()] == expected
+
+
+@pytest.mark.parametrize(
+    "_",
+    [
+        None,
+        pytest.lazy_fixture("part_resource"),
+        pytest.lazy_fixture("part_parameters_resource"),
+    ],
+)
+def test_conditional_select_resource_error(
+    httpserver, driver_name, token_resource, categories_resource, _
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare("SELECT * FROM Resistors WHERE pk = 1")
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    assert ret == pypyodbc.SQL_ERROR
+    with pytest.raises(pypyodbc.Error) as exception:
+        # Because SQLExecute was updated directly, also call:
+        pypyodbc.check_success(crsr, ret)
+    assert "Unable to fetch parts" in exception.value.args[1]
+
+
+def serve_pages(
+    httpserver, resource, items, page_size, enforced, args="", requested=None
+):
+    for offset in range(0, len(items), page_size):
+        query = f"{args}limit={page_size}&offset={offset}"
+        if enforced and offset == 0:
+            # The server paginates even when not asked to
+            query = args.rstrip("&")
+        elif requested and offset == 0:
+            # The server caps the size of pages below the size asked for
+            query = f"{args}limit={requested}&offset=0"
+        httpserver.expect_request(resource, query_string=query).respond_with_json(
+            {
+                "count": len(items),
+                "next": None,
+                "previous": None,
+                "results": items[offset : offset + page_size],
+            }
+        )
+
+
+@pytest.mark.parametrize(
+    "options, enforced, page_size",
+    [
+        ("pagesize=2", False, 2),
+        ("pagesize=2;parallelpages=yes", False, 2),
+        ("", True, 2),
+        ("parallelpages=yes", True, 2),
+        ("pagesize=3", False, 1),
+        ("pagesize=3;parallelpages=yes", False, 1),
+    ],
+)
+def test_select_paginated(
+    httpserver, driver_name, token_resource, options, enforced, page_size
+):
+    categories = [
+        {"pk": 6, "pathstring": "Capacitors"},
+        {"pk": 8, "pathstring": "Capacitors/Aluminium"},
+        {"pk": 59, "pathstring": "Resistors"},
+    ]
+    # With a page size of 1 the server caps the pages asked for with 3
+    requested = 3 if page_size == 1 else None
+    serve_pages(
+        httpserver,
+        "/api/part/category/",
+        categories,
+        page_size,
+        enforced,
+        requested=requested,
+    )
+    serve_pages(
+        httpserver, "/api/part/", parts, page_size, enforced, "category=59&", requested
+    )
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf;"
+        f"fetchparameters=no;{options}"
+    )
+    crsr = cnxn.cursor()
+    crsr.execute("SELECT pk FROM Resistors")
+
+    assert [tuple(row) for row in crsr.fetchall()] == [
+        (part["pk"],) for part in parts
+    ]
+
+
+@pytest.mark.parametrize(
+    "status, body, sqlstate, expected",
+    [
+        (500, {"error": "Database is unavailable"}, "08S01", "Database is unavailable"),
+        (502, "Bad Gateway", "08S01", "502"),
+        (401, {"detail": "Invalid token."}, "28000", "Invalid token."),
+        (403, {"detail": "Permission denied"}, "28000", "Permission denied"),
+    ],
+)
+def test_select_http_error(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    status,
+    body,
+    sqlstate,
+    expected,
+):
+    response = httpserver.expect_request("/api/part/")
+    if isinstance(body, dict):
+        response.respond_with_json(body, status=status)
+    else:
+        response.respond_with_data(body, status=status)
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    with pytest.raises(pypyodbc.Error) as exception:
+        crsr.execute("SELECT * FROM Resistors")
+    assert sqlstate == exception.value.args[0]
+    assert "Unable to fetch parts" in exception.value.args[1]
+    assert expected in exception.value.args[1]
+
+
+@pytest.fixture
+def rotated_token_resource(httpserver):
+    # The token fetched when connecting stops working, and a new one is fetched
+    httpserver.expect_oneshot_request("/api/user/token").respond_with_json(
+        {"token": "old"}
+    )
+    httpserver.expect_request("/api/user/token").respond_with_json({"token": "new"})
+    httpserver.expect_request(
+        "/api/part/", headers={"Authorization": "Token old"}
+    ).respond_with_json({"detail": "Invalid token."}, status=401)
+    httpserver.expect_request(
+        "/api/part/", headers={"Authorization": "Token new"}
+    ).respond_with_json(parts)
+
+
+def test_select_renewed_token(
+    httpserver, driver_name, rotated_token_resource, categories_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.execute("SELECT pk FROM Resistors")
+
+    assert [tuple(row) for row in crsr.fetchall()] == [
+        (part["pk"],) for part in parts
+    ]
+    token_requests = [
+        request for request, _ in httpserver.log if request.path == "/api/user/token"
+    ]
+    assert len(token_requests) == 2
+
+
+def test_select_rejected_token(
+    httpserver, driver_name, rotated_token_resource, categories_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(f"Driver={driver_name};server={server};apitoken=old")
+    crsr = cnxn.cursor()
+    with pytest.raises(pypyodbc.Error) as exception:
+        crsr.execute("SELECT pk FROM Resistors")
+    assert "28000" == exception.value.args[0]
+    assert "no username and password" in exception.value.args[1]
+
+
+@pytest.mark.parametrize(
+    "query, expected",
+    [
+        ("SELECT pk FROM Resistors WHERE pk = 999", []),
+        ("SELECT pk FROM Resistors WHERE pk IN (16, 999)", [(16,)]),
+    ],
+)
+def test_select_missing_part(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    part_resource,
+    part_parameters_resource,
+    query,
+    expected,
+):
+    httpserver.expect_request("/api/part/999/").respond_with_json(
+        {"detail": "Not found."}, status=404
+    )
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.execute(query)
+
+    # A part which doesn't exist is no row rather than an error
+    assert [tuple(row) for row in crsr.fetchall()] == expected
+
+
+@pytest.mark.parametrize(
+    "query, expected",
+    [
+        ("SELECT pk, parameter.Package FROM Resistors WHERE pk = 16", [(16, None)]),
+        (
+            "SELECT pk, parameter.Package FROM Resistors WHERE pk IN (16, 37)",
+            [(16, None), (37, None)],
+        ),
+    ],
+)
+def test_parameters_unavailable(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    part_resource,
+    query,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(query)
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    # The parts are returned without their parameters, with a warning
+    assert ret == pypyodbc.SQL_SUCCESS_WITH_INFO
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    assert [tuple(row) for row in crsr.fetchall()] == expected
+
+
+def test_conditional_select(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    part_resource,
+    part_parameters_resource,
+):
+    # TODO: check the category in query string for parts request
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare("SELECT * FROM Resistors WHERE pk = 30")
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+    assert (
+        ret == pypyodbc.SQL_SUCCESS
+    )  # redundant, but what we actually are trying to do
+    # Because SQLExecute was updated directly, also call:
+    pypyodbc.check_success(crsr, ret)
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    results = crsr.fetchall()
+    assert len(results) == 1
+
+
+@pytest.mark.parametrize(
+    "condition, expected",
+    [
+        ("IPN LIKE 'RES-%'", [16, 37]),
+        ("IPN NOT LIKE 'RES-%'", [18, 30]),
+        ("IPN LIKE '_AP-0000_0-00'", [30]),
+        ("name LIKE '%r 0\\%%' {escape '\\'}", [16]),
+        ("name LIKE '%1 !%' ESCAPE '!'", [37]),
+        ("link LIKE '%'", [16, 37, 18]),
+        ("IPN LIKE 'RES%%%00'", [16, 37]),
+        ("name LIKE '%0%0%0%5'", [16, 18]),
+        ("pk IN (12, 18, 30)", [18, 30]),
+        ("pk NOT IN (12, 18, 30)", [16, 37]),
+        ("pk NOT IN (12, NULL)", []),
+        ("IPN IN ('RES-000037-00', 'CAP-000015-00')", [37, 18]),
+        ("in_stock BETWEEN 50 AND 100", [16, 37]),
+        ("in_stock NOT BETWEEN 50 AND 100", [18, 30]),
+        ("pk BETWEEN 16 AND 30 AND IPN LIKE 'CAP%'", [18, 30]),
+    ],
+)
+def test_conditional_select_predicate(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    category_parameters_resource,
+    condition,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(f"SELECT * FROM Resistors WHERE {condition}")
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    pk = [column[0] for column in crsr.description].index("pk")
+    assert [row[pk] for row in crsr.fetchall()] == expected
+
+
+@pytest.mark.parametrize(
+    "condition, state, message",
+    [
+        (
+            "name LIKE '100\\' {escape '\\'}",
+            "22025",
+            "Invalid escape sequence at position 41",
+        ),
+        (
+            "name LIKE '100' ESCAPE 'ab'",
+            "22019",
+            "Invalid escape character at position 54",
+        ),
+    ],
+)
+def test_conditional_select_invalid_like(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    condition,
+    state,
+    message,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(f"SELECT * FROM Resistors WHERE {condition}")
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    assert ret == pypyodbc.SQL_ERROR
+    with pytest.raises(pypyodbc.Error) as exception:
+        # Because SQLExecute was updated directly, also call:
+        pypyodbc.check_success(crsr, ret)
+    assert state == exception.value.args[0]
+    assert message in exception.value.args[1]
+
+
+@pytest.mark.parametrize(
+    "query, expected",
+    [
+        ("SELECT pk FROM Resistors ORDER BY in_stock DESC, pk", [18, 16, 37, 30]),
+        (
+            "SELECT pk, in_stock AS s FROM Resistors ORDER BY s, 1 DESC",
+            [30, 37, 16, 18],
+        ),
+        ("SELECT pk FROM Resistors ORDER BY link, pk DESC", [30, 37, 18, 16]),
+        (
+            "SELECT pk FROM Resistors WHERE active ORDER BY revision DESC, IPN DESC",
+            [30, 37, 16, 18],
+        ),
+        ("SELECT pk FROM Resistors ORDER BY revision, IPN LIMIT 2", [18, 16]),
+        ("SELECT pk FROM Resistors WHERE active LIMIT 2 OFFSET 3", [30]),
+        ("SELECT pk FROM Resistors ORDER BY in_stock LIMIT 2 OFFSET 1", [16, 37]),
+        ("SELECT pk FROM Resistors WHERE pk IN (37, 16) ORDER BY pk", [16, 37]),
+    ],
+)
+def test_order_by(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    query,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(query)
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    assert [row[0] for row in crsr.fetchall()] == expected
+
+
+@pytest.mark.part_mods(
+    [
+        ("/0/creation_date", "2023-05-01"),
+        ("/1/creation_date", "2023-06-11"),
+        ("/2/creation_date", "2023-07-21"),
+        ("/3/creation_date", "2023-08-01"),
+        ("/0/revision", "2023-06-11"),
+    ]
+)
+@pytest.mark.parametrize(
+    "query, params, expected",
+    [
+        (
+            "SELECT pk, creation_date FROM Resistors WHERE creation_date >= {d '2023-06-11'}",
+            None,
+            [
+                (37, datetime.date(2023, 6, 11)),
+                (18, datetime.date(2023, 7, 21)),
+                (30, datetime.date(2023, 8, 1)),
+            ],
+        ),
+        (
+            "SELECT pk FROM Resistors WHERE creation_date > {ts '2023-06-11 12:00:00'}",
+            None,
+            [(18,), (30,)],
+        ),
+        (
+            "SELECT pk FROM Resistors WHERE creation_date BETWEEN '2023-06-01' AND '2023-07-31'",
+            None,
+            [(37,), (18,)],
+        ),
+        (
+            "SELECT pk FROM Resistors WHERE creation_date < ?",
+            [datetime.date(2023, 6, 12)],
+            [(16,), (37,)],
+        ),
+        (
+            "SELECT MAX(creation_date), CAST(MIN(creation_date) AS TIMESTAMP) FROM Resistors",
+            None,
+            [(datetime.date(2023, 8, 1), datetime.datetime(2023, 5, 1))],
+        ),
+        # Only date fields are dates, other strings stay strings
+        ("SELECT revision FROM Resistors WHERE pk = 16", None, [("2023-06-11",)]),
+    ],
+)
+def test_dates(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    query,
+    params,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.execute(query, params)
+
+    assert [tuple(row) for row in crsr.fetchall()] == expected
+
+
+@pytest.mark.parametrize(
+    "query, expected",
+    [
+        (
+            "SELECT COUNT(*), COUNT(link), MIN(in_stock), MAX(IPN), SUM(pk), AVG(in_stock) FROM Resistors",
+            [(4, 3, 30.0, "RES-000037-00", 101, 107.5)],
+        ),
+        ("SELECT COUNT(*), AVG(pk) FROM Resistors WHERE pk < 0", [(0, None)]),
+        (
+            "SELECT revision, COUNT(*) AS n FROM Resistors GROUP BY revision ORDER BY n DESC",
+            [("", 3), ("A", 1)],
+        ),
+        (
+            "SELECT in_stock, COUNT(*) FROM Resistors GROUP BY 1 HAVING COUNT(*) > 1",
+            [(100.0, 2)],
+        ),
+        (
+            "SELECT parameter.Package, AVG(pk) FROM Resistors GROUP BY parameter.Package",
+            [("0805", 25.25)],
+        ),
+        (
+            "SELECT DISTINCT in_stock FROM Resistors ORDER BY 1 DESC",
+            [(200.0,), (100.0,), (30.0,)],
+        ),
+        ("SELECT DISTINCT active, link FROM Resistors", [(1, ""), (1, None)]),
+        ("SELECT COUNT(DISTINCT in_stock) FROM Resistors", [(3,)]),
+        # Strings holding numbers are still different strings
+        pytest.param(
+            "SELECT DISTINCT revision FROM Resistors",
+            [("0603",), ("603",), ("A",)],
+            marks=pytest.mark.part_mods(
+                [
+                    ("/0/revision", "0603"),
+                    ("/1/revision", "603"),
+                    ("/2/revision", "603"),
+                ]
+            ),
+        ),
+        pytest.param(
+            "SELECT revision, COUNT(*) FROM Resistors GROUP BY revision",
+            [("0603", 1), ("603", 2), ("A", 1)],
+            marks=pytest.mark.part_mods(
+                [
+                    ("/0/revision", "0603"),
+                    ("/1/revision", "603"),
+                    ("/2/revision", "603"),
+                ]
+            ),
+        ),
+        pytest.param(
+            "SELECT COUNT(DISTINCT revision) FROM Resistors",
+            [(4,)],
+            marks=pytest.mark.part_mods(
+                [
+                    ("/0/revision", "0603"),
+                    ("/1/revision", "603"),
+                    ("/2/revision", "603.0"),
+                ]
+            ),
+        ),
+    ],
+)
+def test_aggregates(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    category_parameters_resource,
+    query,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare(query)
+    # pypyodbc doesn't allow us to execute the prepares statements
     # unless we call the SQLExecute function directly
     ret = pypyodbc.SQLExecute(crsr.stmt_h)
-    assert ret == pypyodbc.SQL_ERROR
-    with pytest.raises(pypyodbc.Error) as exception:
-        # Because SQLExecute was updated directly, also call:
+    if ret != pypyodbc.SQL_SUCCESS:
         pypyodbc.check_success(crsr, ret)
-    assert "Unable to fetch parts" in exception.value.args[1]
-    assert "Category does not exist" in exception.value.args[1]
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
 
+    assert [tuple(row) for row in crsr.fetchall()] == expected
 
-def test_unconditional_select_resource_error(
-    httpserver, driver_name, token_resource, categories_resource
+
+@pytest.mark.parametrize(
+    "query, message",
+    [
+        (
+            "SELECT pk FROM Resistors WHERE COUNT(*) > 1",
+            "Aggregate function COUNT is not allowed here at position 32",
+        ),
+        (
+            "SELECT MAX(COUNT(*)) FROM Resistors",
+            "Aggregate function COUNT is not allowed here at position 12",
+        ),
+        (
+            "SELECT COUNT(*) FROM Resistors GROUP BY 2",
+            "GROUP BY position 2 is not in the select list at position 41",
+        ),
+    ],
+)
+def test_aggregates_invalid(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    query,
+    message,
 ):
     server = httpserver.url_for("")
     cnxn = pypyodbc.connect(
         f"Driver={driver_name};server={server};username=asdf;password=asdf"
     )
     crsr = cnxn.cursor()
-    crsr.prepare("SELECT * FROM Resistors")
+    crsr.prepare(query)
     # pypyodbc doesn't allow us to execute the prepares statements
     # unless we call the SQLExecute function directly
     ret = pypyodbc.SQLExecute(crsr.stmt_h)
@@ -378,25 +2082,54 @@ def test_unconditional_select_resource_error(
     with pytest.raises(pypyodbc.Error) as exception:
         # Because SQLExecute was updated directly, also call:
         pypyodbc.check_success(crsr, ret)
-    assert "Unable to fetch parts" in exception.value.args[1]
+    assert "42000" == exception.value.args[0]
+    assert message in exception.value.args[1]
 
 
 @pytest.mark.parametrize(
-    "query",
+    "query, expected",
     [
-        "SELECT * FROM Resistors",
-        "SELECT IPN FROM Resistors",
-        'SELECT "IPN" FROM Resistors',
-        "SELECT IPN, pk FROM Resistors",
-        'SELECT "IPN", "pk" FROM Resistors',
-        "SELECT IPN,pk FROM Resistors",
-        'SELECT "IPN","pk" FROM Resistors',
+        (
+            "SELECT {fn UCASE(IPN)}, LOWER(IPN) || '/' || CAST(pk AS VARCHAR) FROM Resistors WHERE pk IN (16, 37)",
+            [
+                ("RES-000014-00", "res-000014-00/16"),
+                ("RES-000037-00", "res-000037-00/37"),
+            ],
+        ),
+        (
+            "SELECT SUBSTRING(IPN, 1, 3), {fn LENGTH(revision)}, ROUND(in_stock, -2), CAST(in_stock AS INTEGER) FROM Resistors",
+            [
+                ("RES", 0, 100.0, 100),
+                ("RES", 0, 100.0, 100),
+                ("CAP", 0, 200.0, 200),
+                ("CAP", 1, 0.0, 30),
+            ],
+        ),
+        (
+            "SELECT COALESCE(link, 'none'), CONCAT(IPN, ' ', parameter.Package) FROM Resistors",
+            [
+                ("", "RES-000014-00 0805"),
+                ("", "RES-000037-00 0805"),
+                ("", "CAP-000015-00 0805"),
+                ("none", "CAP-000030-00 0805"),
+            ],
+        ),
+        (
+            "SELECT pk FROM Resistors WHERE {fn UCASE(name)} LIKE '%RESISTOR%'",
+            [(16,)],
+        ),
     ],
 )
-def test_unconditional_select(
-    httpserver, driver_name, token_resource, categories_resource, parts_resource, query
+def test_scalar_functions(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    category_parameters_resource,
+    query,
+    expected,
 ):
-    # TODO: check the category in query string for parts request
     server = httpserver.url_for("")
     cnxn = pypyodbc.connect(
         f"Driver={driver_name};server={server};username=asdf;password=asdf"
@@ -408,27 +2141,40 @@ def test_unconditional_select(
     ret = pypyodbc.SQLExecute(crsr.stmt_h)
     if ret != pypyodbc.SQL_SUCCESS:
         pypyodbc.check_success(crsr, ret)
-    assert (
-        ret == pypyodbc.SQL_SUCCESS
-    )  # redundant, but what we actually are trying to do
-    # Because SQLExecute was updated directly, also call:
-    pypyodbc.check_success(crsr, ret)
     crsr._NumOfRows()
     crsr._UpdateDesc()
 
-    results = crsr.fetchall()
-    assert len(results) == 4
+    assert [tuple(row) for row in crsr.fetchall()] == expected
 
 
-def test_conditional_select_invalid_condition_column(
-    httpserver, driver_name, token_resource, categories_resource
+@pytest.mark.parametrize(
+    "query, expected",
+    [
+        (
+            "SELECT CAST(name AS INTEGER) FROM Resistors",
+            ("22018", "Invalid character value for cast at position 8"),
+        ),
+        (
+            "SELECT SUBSTRING(IPN, 1, -1) FROM Resistors",
+            ("22011", "Negative length for SUBSTRING at position 8"),
+        ),
+    ],
+)
+def test_scalar_functions_invalid(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    query,
+    expected,
 ):
     server = httpserver.url_for("")
     cnxn = pypyodbc.connect(
         f"Driver={driver_name};server={server};username=asdf;password=asdf"
     )
     crsr = cnxn.cursor()
-    crsr.prepare("SELECT * FROM Pizzas WHERE qqq = 1")
+    crsr.prepare(query)
     # pypyodbc doesn't allow us to execute the prepares statements
     # unless we call the SQLExecute function directly
     ret = pypyodbc.SQLExecute(crsr.stmt_h)
@@ -436,27 +2182,72 @@ def test_conditional_select_invalid_condition_column(
     with pytest.raises(pypyodbc.Error) as exception:
         # Because SQLExecute was updated directly, also call:
         pypyodbc.check_success(crsr, ret)
-    assert "Unable to fetch parts" in exception.value.args[1]
-    assert "invalid filter column" in exception.value.args[1]
+    assert expected[0] == exception.value.args[0]
+    assert expected[1] in exception.value.args[1]
 
 
 @pytest.mark.parametrize(
-    "_",
+    "query, names, expected",
     [
-        None,
-        pytest.lazy_fixture("part_resource"),
-        pytest.lazy_fixture("part_parameters_resource"),
+        (
+            "SELECT r.pk, c.IPN FROM Resistors r JOIN Capacitors c ON r.pk = c.pk WHERE c.IPN LIKE 'CAP%'",
+            ["r.pk", "c.IPN"],
+            [(18, "CAP-000015-00"), (30, "CAP-000030-00")],
+        ),
+        (
+            "SELECT a.pk, b.pk FROM Resistors a JOIN Resistors b ON a.in_stock = b.in_stock AND a.pk < b.pk",
+            ["a.pk", "b.pk"],
+            [(16, 37)],
+        ),
+        (
+            "SELECT r.pk, c.pk FROM Resistors r LEFT JOIN Capacitors c ON r.pk = c.pk AND c.revision = 'A' ORDER BY r.pk",
+            ["r.pk", "c.pk"],
+            [(16, None), (18, None), (30, 30), (37, None)],
+        ),
+        (
+            "SELECT r.in_stock, COUNT(c.pk) AS matches FROM Resistors r JOIN Capacitors c ON r.in_stock = c.in_stock GROUP BY r.in_stock ORDER BY 1",
+            ["r.in_stock", "matches"],
+            [(30.0, 1), (100.0, 4), (200.0, 1)],
+        ),
     ],
 )
-def test_conditional_select_resource_error(
-    httpserver, driver_name, token_resource, categories_resource, _
+def test_join(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    query,
+    names,
+    expected,
 ):
     server = httpserver.url_for("")
     cnxn = pypyodbc.connect(
         f"Driver={driver_name};server={server};username=asdf;password=asdf"
     )
     crsr = cnxn.cursor()
-    crsr.prepare("SELECT * FROM Resistors WHERE pk = 1")
+    crsr.prepare(query)
+    # pypyodbc doesn't allow us to execute the prepares statements
+    # unless we call the SQLExecute function directly
+    ret = pypyodbc.SQLExecute(crsr.stmt_h)
+    if ret != pypyodbc.SQL_SUCCESS:
+        pypyodbc.check_success(crsr, ret)
+    crsr._NumOfRows()
+    crsr._UpdateDesc()
+
+    assert [d[0].lower() for d in crsr.description] == [n.lower() for n in names]
+    assert [tuple(row) for row in crsr.fetchall()] == expected
+
+
+def test_join_ambiguous_column(
+    httpserver, driver_name, token_resource, categories_resource, parts_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    crsr.prepare("SELECT IPN FROM Resistors r JOIN Capacitors c ON r.pk = c.pk")
     # pypyodbc doesn't allow us to execute the prepares statements
     # unless we call the SQLExecute function directly
     ret = pypyodbc.SQLExecute(crsr.stmt_h)
@@ -464,39 +2255,76 @@ def test_conditional_select_resource_error(
     with pytest.raises(pypyodbc.Error) as exception:
         # Because SQLExecute was updated directly, also call:
         pypyodbc.check_success(crsr, ret)
-    assert "Unable to fetch parts" in exception.value.args[1]
+    assert "42000" == exception.value.args[0]
+    assert "Ambiguous column name at position 8: IPN" in exception.value.args[1]
 
 
-def test_conditional_select(
+@pytest.mark.parametrize(
+    "query, query_string, response, expected",
+    [
+        (
+            "SELECT pk FROM Resistors ORDER BY IPN DESC, name",
+            {"category": "59", "ordering": "-IPN,name"},
+            [parts[3], parts[1], parts[0], parts[2]],
+            [30, 37, 16, 18],
+        ),
+        (
+            "SELECT pk FROM Resistors ORDER BY pk LIMIT 2 OFFSET 1",
+            {"category": "59", "ordering": "pk", "limit": "2", "offset": "1"},
+            {"count": 4, "next": None, "previous": None, "results": parts[1:3]},
+            [37, 18],
+        ),
+        (
+            "SELECT pk AS key FROM Resistors ORDER BY key DESC LIMIT 1",
+            {"category": "59", "ordering": "-pk", "limit": "1"},
+            {"count": 4, "next": None, "previous": None, "results": parts[1:2]},
+            [37],
+        ),
+        (
+            "SELECT pk FROM Resistors LIMIT 1",
+            {"category": "59", "limit": "1"},
+            {"count": 4, "next": None, "previous": None, "results": parts[0:1]},
+            [16],
+        ),
+        # A server which ignores the limit and returns the whole list
+        (
+            "SELECT pk FROM Resistors LIMIT 2 OFFSET 1",
+            {"category": "59", "limit": "2", "offset": "1"},
+            parts,
+            [37, 18],
+        ),
+    ],
+)
+def test_order_by_pushdown(
     httpserver,
     driver_name,
     token_resource,
     categories_resource,
-    part_resource,
-    part_parameters_resource,
+    query,
+    query_string,
+    response,
+    expected,
 ):
-    # TODO: check the category in query string for parts request
+    # The server is trusted with the ordering and limiting, so the expected
+    # results are in the order the server returns them
+    httpserver.expect_request(
+        "/api/part/", query_string=query_string
+    ).respond_with_json(response)
     server = httpserver.url_for("")
     cnxn = pypyodbc.connect(
         f"Driver={driver_name};server={server};username=asdf;password=asdf"
     )
     crsr = cnxn.cursor()
-    crsr.prepare("SELECT * FROM Resistors WHERE pk = 30")
+    crsr.prepare(query)
     # pypyodbc doesn't allow us to execute the prepares statements
     # unless we call the SQLExecute function directly
     ret = pypyodbc.SQLExecute(crsr.stmt_h)
     if ret != pypyodbc.SQL_SUCCESS:
         pypyodbc.check_success(crsr, ret)
-    assert (
-        ret == pypyodbc.SQL_SUCCESS
-    )  # redundant, but what we actually are trying to do
-    # Because SQLExecute was updated directly, also call:
-    pypyodbc.check_success(crsr, ret)
     crsr._NumOfRows()
     crsr._UpdateDesc()
 
-    results = crsr.fetchall()
-    assert len(results) == 1
+    assert [row[0] for row in crsr.fetchall()] == expected
 
 
 @pytest.mark.skipif(
@@ -611,3 +2439,153 @@ def test_tables(
     tables = cnxn.cursor().tables(table=table)
 
     assert set(tables) == set(expected)
+
+
+@pytest.mark.parametrize(
+    "table, column, expected",
+    [
+        (
+            "Resistors",
+            "parameter.%",
+            [
+                ("parameter.Package", 12, 255, 1, "YES"),
+                ("parameter.Power", 12, 255, 1, "YES"),
+            ],
+        ),
+        ("Resistors", "pk", [("pk", -5, 20, 0, "NO")]),
+        ("Res_stors", "IPN", [("IPN", 12, 100, 1, "YES")]),
+        ("Resistors", "creation%", [("creation_date", 91, 10, 1, "YES")]),
+        ("Nope", None, []),
+    ],
+)
+def test_columns(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    part_options_resource,
+    category_parameter_templates_resource,
+    parts_resource,
+    category_parameters_resource,
+    table,
+    column,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    crsr = cnxn.cursor()
+    columns = crsr.columns(table=table, column=column).fetchall()
+
+    assert [(row[3], row[4], row[6], row[10], row[17]) for row in columns] == expected
+    # The columns come from the schema, without listing the parts
+    assert not [
+        request
+        for request, _ in httpserver.log
+        if request.path == "/api/part/" and request.method == "GET"
+    ]
+    # Every column is numbered, in the order SELECT * gives them, by name
+    all_columns = crsr.columns(table="Resistors").fetchall()
+    assert [row[16] for row in all_columns] == list(range(1, len(all_columns) + 1))
+    assert [row[3] for row in all_columns] == sorted(row[3] for row in all_columns)
+
+
+@pytest.mark.parametrize(
+    "column, expected",
+    [
+        ("IPN", [("IPN", 12, 255, 1, "YES")]),
+        ("pk", [("pk", -5, 20, 0, "NO")]),
+        (
+            "parameter.%",
+            [
+                ("parameter.Breakdown Voltage", 12, 255, 1, "YES"),
+                ("parameter.Clamping Voltage", 12, 255, 1, "YES"),
+            ],
+        ),
+    ],
+)
+def test_columns_without_schema(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    parts_resource,
+    part_parameters_resource,
+    column,
+    expected,
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    columns = cnxn.cursor().columns(table="Resistors", column=column).fetchall()
+
+    assert [(row[3], row[4], row[6], row[10], row[17]) for row in columns] == expected
+
+
+def test_columns_unavailable(
+    httpserver, driver_name, token_resource, categories_resource
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+
+    # Without a schema or parts to sample there are no columns to give, which
+    # isn't an error
+    assert cnxn.cursor().columns(table="Resistors").fetchall() == []
+
+
+@pytest.mark.parametrize(
+    "table, expected",
+    [
+        ("Resistors", [(None, None, "Resistors", "pk", 1, "pk")]),
+        ("Nope", []),
+    ],
+)
+def test_primary_keys(
+    httpserver, driver_name, token_resource, categories_resource, table, expected
+):
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    keys = cnxn.cursor().primaryKeys(table=table).fetchall()
+
+    assert [tuple(row) for row in keys] == expected
+
+
+@pytest.mark.parametrize(
+    "allow_duplicate_ipn, expected",
+    [
+        ("False", ["IPN", "pk"]),
+        ("True", ["pk"]),
+        (False, ["IPN", "pk"]),
+        (None, ["pk"]),
+    ],
+)
+def test_statistics(
+    httpserver,
+    driver_name,
+    token_resource,
+    categories_resource,
+    allow_duplicate_ipn,
+    expected,
+):
+    if allow_duplicate_ipn is not None:
+        httpserver.expect_request(
+            "/api/settings/global/PART_ALLOW_DUPLICATE_IPN/"
+        ).respond_with_json(
+            {"pk": 1, "key": "PART_ALLOW_DUPLICATE_IPN", "value": allow_duplicate_ipn}
+        )
+    server = httpserver.url_for("")
+    cnxn = pypyodbc.connect(
+        f"Driver={driver_name};server={server};username=asdf;password=asdf"
+    )
+    indexes = cnxn.cursor().statistics(table="Resistors").fetchall()
+
+    # (NON_UNIQUE, INDEX_NAME, COLUMN_NAME) of the unique indexes
+    assert [(row[3], row[5], row[8]) for row in indexes] == [
+        (0, column, column) for column in expected
+    ]
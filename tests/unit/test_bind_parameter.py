@@ -8,14 +8,25 @@ def test_invalid_handle(C):
     )
 
 
-def test_valid(C, stmt_handle):
+@pytest.mark.parametrize(
+    "value_type",
+    [
+        "SQL_C_CHAR",
+        "SQL_C_WCHAR",
+        "SQL_C_SLONG",
+        "SQL_C_SBIGINT",
+        "SQL_C_DOUBLE",
+        "SQL_C_BIT",
+    ],
+)
+def test_valid(C, stmt_handle, value_type):
     buffer = C.ffi.new("SQLCHAR[]", 100)
     assert (
         C.SQLBindParameter(
             stmt_handle,
-            0,
+            1,
             C.SQL_PARAM_INPUT,
-            C.SQL_C_CHAR,
+            getattr(C, value_type),
             0,
             0,
             0,
@@ -46,7 +57,7 @@ def test_invalid_input_output_types(C, stmt_handle, input_output_type):
 
 
 @pytest.mark.parametrize(
-    "value_type", ["SQL_C_WCHAR", "SQL_C_FLOAT", "SQL_C_SSHORT", "SQL_C_ULONG"]
+    "value_type", ["SQL_C_FLOAT", "SQL_C_SSHORT", "SQL_C_ULONG"]
 )
 def test_invalid_value_types(C, stmt_handle, value_type):
     assert (
@@ -64,3 +75,22 @@ def test_invalid_value_types(C, stmt_handle, value_type):
         )
         == C.SQL_ERROR
     )
+
+
+def test_invalid_parameter_number(C, stmt_handle):
+    buffer = C.ffi.new("SQLCHAR[]", 100)
+    assert (
+        C.SQLBindParameter(
+            stmt_handle,
+            0,
+            C.SQL_PARAM_INPUT,
+            C.SQL_C_CHAR,
+            0,
+            0,
+            0,
+            buffer,
+            len(buffer),
+            C.NULL,
+        )
+        == C.SQL_ERROR
+    )
@@ -3,3 +3,10 @@ def test_invalid_handle(C):
         C.SQLDescribeCol(C.NULL, 0, C.NULL, 0, C.NULL, C.NULL, C.NULL, C.NULL, C.NULL)
         == C.SQL_INVALID_HANDLE
     )
+
+
+def test_invalid_handle_w(C):
+    assert (
+        C.SQLDescribeColW(C.NULL, 0, C.NULL, 0, C.NULL, C.NULL, C.NULL, C.NULL, C.NULL)
+        == C.SQL_INVALID_HANDLE
+    )
@@ -30,12 +30,26 @@ def test_invalid_handle(C):
     )
 
 
+def prepare(C, stmt_handle, statement):
+    assert C.SQLPrepare(stmt_handle, statement, len(statement)) == C.SQL_SUCCESS
+
+
+def test_not_prepared(C, stmt_handle, get_diag_rec):
+    assert (
+        C.SQLDescribeParam(stmt_handle, 1, C.NULL, C.NULL, C.NULL, C.NULL)
+        == C.SQL_ERROR
+    )
+    sql_state, _ = get_diag_rec()
+    assert C.ffi.string(sql_state) == b"HY010"
+
+
 def test_param_one(C, stmt_handle):
     data_type_ptr = C.ffi.new("SQLSMALLINT *")
     parameter_size_ptr = C.ffi.new("SQLULEN *")
     decimal_digits_ptr = C.NULL
     nullable_ptr = C.ffi.new("SQLSMALLINT *")
 
+    prepare(C, stmt_handle, b"SELECT * FROM Resistors WHERE IPN = ?")
     assert (
         C.SQLDescribeParam(
             stmt_handle,
@@ -48,11 +62,37 @@ def test_param_one(C, stmt_handle):
         == C.SQL_SUCCESS
     )
     assert data_type_ptr[0] == C.SQL_VARCHAR
-    # assert parameter_size_ptr[0] == C.SQL_NO_TOTAL    Not sure this is correct
-    assert nullable_ptr[0] == C.SQL_NO_NULLS
+    assert nullable_ptr[0] == C.SQL_NULLABLE
+
+
+@pytest.mark.parametrize(
+    "parameter_number, data_type",
+    [
+        (1, "SQL_BIGINT"),
+        (2, "SQL_VARCHAR"),
+        (3, "SQL_DOUBLE"),
+        (4, "SQL_VARCHAR"),
+    ],
+)
+def test_param_types(C, stmt_handle, parameter_number, data_type):
+    data_type_ptr = C.ffi.new("SQLSMALLINT *")
+
+    prepare(
+        C,
+        stmt_handle,
+        b"SELECT * FROM Resistors WHERE pk = ? OR IPN IN (?, 'x') OR in_stock BETWEEN ? AND 10 OR name LIKE ?",
+    )
+    assert (
+        C.SQLDescribeParam(
+            stmt_handle, parameter_number, data_type_ptr, C.NULL, C.NULL, C.NULL
+        )
+        == C.SQL_SUCCESS
+    )
+    assert data_type_ptr[0] == getattr(C, data_type)
 
 
 def test_invalid_param(C, stmt_handle, get_diag_rec):
+    prepare(C, stmt_handle, b"SELECT * FROM Resistors WHERE IPN = ?")
     assert (
         C.SQLDescribeParam(stmt_handle, 2, C.NULL, C.NULL, C.NULL, C.NULL)
         == C.SQL_ERROR
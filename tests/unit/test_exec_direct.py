@@ -0,0 +1,37 @@
+import pytest
+
+
+@pytest.fixture
+def get_diag_rec(C, stmt_handle):
+    def fn():
+        sql_state = C.ffi.new("SQLCHAR[]", 6)
+        buffer = C.ffi.new("SQLCHAR[]", 1000)
+        text_len = C.ffi.new("SQLSMALLINT*")
+        result = C.SQLGetDiagRec(
+            C.SQL_HANDLE_STMT,
+            stmt_handle,
+            1,
+            sql_state,
+            C.NULL,
+            buffer,
+            len(buffer),
+            text_len,
+        )
+        assert result == C.SQL_SUCCESS
+        return sql_state, C.ffi.string(buffer)
+
+    return fn
+
+
+def test_invalid_handle(C):
+    assert C.SQLExecDirect(C.NULL, C.NULL, 0) == C.SQL_INVALID_HANDLE
+
+
+def test_syntax_error(C, stmt_handle, get_diag_rec):
+    statement = b"SELECT FROM Resistors"
+    assert (
+        C.SQLExecDirect(stmt_handle, statement, len(statement)) == C.SQL_ERROR
+    )
+    sql_state, message = get_diag_rec()
+    assert C.ffi.string(sql_state) == b"42000"
+    assert b"syntax error at position 8" in message
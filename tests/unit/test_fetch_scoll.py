@@ -1,2 +1,32 @@
 def test_invalid_handle(C):
     assert C.SQLFetchScroll(C.NULL, 0, 0) == C.SQL_INVALID_HANDLE
+
+
+def test_forward_only(C, stmt_handle):
+    assert C.SQLFetchScroll(stmt_handle, C.SQL_FETCH_PRIOR, 0) == C.SQL_ERROR
+
+
+def test_invalid_orientation(C, stmt_handle):
+    assert (
+        C.SQLSetStmtAttr(
+            stmt_handle,
+            C.SQL_ATTR_CURSOR_SCROLLABLE,
+            C.ffi.cast("SQLPOINTER", C.SQL_SCROLLABLE),
+            0,
+        )
+        == C.SQL_SUCCESS
+    )
+    assert C.SQLFetchScroll(stmt_handle, 42, 0) == C.SQL_ERROR
+
+
+def test_before_start(C, stmt_handle):
+    assert (
+        C.SQLSetStmtAttr(
+            stmt_handle,
+            C.SQL_ATTR_CURSOR_TYPE,
+            C.ffi.cast("SQLPOINTER", C.SQL_CURSOR_STATIC),
+            0,
+        )
+        == C.SQL_SUCCESS
+    )
+    assert C.SQLFetchScroll(stmt_handle, C.SQL_FETCH_PRIOR, 0) == C.SQL_NO_DATA
@@ -1,2 +1,10 @@
 def test_invalid_handle(C):
     assert C.SQLGetData(C.NULL, 0, 0, C.NULL, 0, C.NULL) == C.SQL_INVALID_HANDLE
+
+
+def test_not_positioned_on_row(C, stmt_handle):
+    buffer = C.ffi.new("SQLCHAR[]", 10)
+    length = C.ffi.new("SQLLEN*")
+    assert (
+        C.SQLGetData(stmt_handle, 1, C.SQL_C_CHAR, buffer, 10, length) == C.SQL_ERROR
+    )
@@ -63,10 +63,9 @@ def test_error_int(C, force_error, env_handle, diag_identifier, expected):
         ("SQL_DIAG_CLASS_ORIGIN", 3, b"IS", 2, "SQL_SUCCESS_WITH_INFO"),
         ("SQL_DIAG_SUBCLASS_ORIGIN", 100, b"ODBC 3.0", 8, "SQL_SUCCESS"),
         ("SQL_DIAG_SUBCLASS_ORIGIN", 3, b"OD", 2, "SQL_SUCCESS_WITH_INFO"),
-        ("SQL_DIAG_CONNECTION_NAME", 100, b"kom2", 4, "SQL_SUCCESS"),
-        ("SQL_DIAG_CONNECTION_NAME", 3, b"ko", 2, "SQL_SUCCESS_WITH_INFO"),
-        ("SQL_DIAG_SERVER_NAME", 100, b"inventree", 9, "SQL_SUCCESS"),
-        ("SQL_DIAG_SERVER_NAME", 3, b"in", 2, "SQL_SUCCESS_WITH_INFO"),
+        # Environments aren't connected to any data source or server
+        ("SQL_DIAG_CONNECTION_NAME", 100, b"", 0, "SQL_SUCCESS"),
+        ("SQL_DIAG_SERVER_NAME", 100, b"", 0, "SQL_SUCCESS"),
     ],
 )
 def test_error_str(
@@ -93,3 +92,130 @@ def test_error_str(
     assert result == getattr(C, expected_ret)
     assert text_len[0] == expected_len
     assert C.ffi.string(buffer) == expected_str
+
+
+def test_no_error_number(C, env_handle):
+    buffer = C.ffi.new("SQLINTEGER*")
+    result = C.SQLGetDiagField(
+        C.SQL_HANDLE_ENV, env_handle, 0, C.SQL_DIAG_NUMBER, buffer, 0, C.NULL
+    )
+    assert result == C.SQL_SUCCESS
+    assert buffer[0] == 0
+
+
+def test_error_return_code(C, force_error, env_handle):
+    buffer = C.ffi.new("SQLRETURN*")
+    result = C.SQLGetDiagField(
+        C.SQL_HANDLE_ENV, env_handle, 0, C.SQL_DIAG_RETURNCODE, buffer, 0, C.NULL
+    )
+    assert result == C.SQL_SUCCESS
+    assert buffer[0] == C.SQL_ERROR
+
+
+def test_error_cleared(C, force_error, env_handle):
+    # The diagnostics are those of the last function called
+    assert (
+        C.SQLSetEnvAttr(
+            env_handle,
+            C.SQL_ATTR_ODBC_VERSION,
+            C.ffi.cast("SQLPOINTER", C.SQL_OV_ODBC3),
+            0,
+        )
+        == C.SQL_SUCCESS
+    )
+
+    buffer = C.ffi.new("SQLINTEGER*")
+    result = C.SQLGetDiagField(
+        C.SQL_HANDLE_ENV, env_handle, 0, C.SQL_DIAG_NUMBER, buffer, 0, C.NULL
+    )
+    assert result == C.SQL_SUCCESS
+    assert buffer[0] == 0
+    result = C.SQLGetDiagField(
+        C.SQL_HANDLE_ENV, env_handle, 1, C.SQL_DIAG_SQLSTATE, C.NULL, 0, C.NULL
+    )
+    assert result == C.SQL_NO_DATA
+
+
+@pytest.mark.parametrize(
+    "diag_identifier, buffer_type",
+    [
+        ("SQL_DIAG_ROW_NUMBER", "SQLLEN*"),
+        ("SQL_DIAG_COLUMN_NUMBER", "SQLINTEGER*"),
+    ],
+)
+def test_error_row_column_not_statement(
+    C, force_error, env_handle, diag_identifier, buffer_type
+):
+    # Only diagnostics of statements are about rows and columns
+    buffer = C.ffi.new(buffer_type)
+    result = C.SQLGetDiagField(
+        C.SQL_HANDLE_ENV,
+        env_handle,
+        1,
+        getattr(C, diag_identifier),
+        buffer,
+        0,
+        C.NULL,
+    )
+    assert result == C.SQL_ERROR
+
+
+@pytest.fixture
+def connection_refused(C, conn_handle, port):
+    hostname, portnumber = port
+    server = f"http://{hostname}:{portnumber}"
+    connection_string = f"server={server}/;apitoken=asdf".encode()
+    assert (
+        C.SQLDriverConnect(
+            conn_handle,
+            C.NULL,
+            connection_string,
+            len(connection_string),
+            C.NULL,
+            0,
+            C.NULL,
+            0,
+        )
+        == C.SQL_ERROR
+    )
+    return server
+
+
+def test_connection_error_names(C, connection_refused, conn_handle):
+    buffer = C.ffi.new("SQLCHAR[]", 100)
+    text_len = C.ffi.new("SQLSMALLINT*")
+    result = C.SQLGetDiagField(
+        C.SQL_HANDLE_DBC,
+        conn_handle,
+        1,
+        C.SQL_DIAG_SERVER_NAME,
+        buffer,
+        100,
+        text_len,
+    )
+    assert result == C.SQL_SUCCESS
+    assert C.ffi.string(buffer) == connection_refused.encode()
+
+    # Connected without a DSN
+    result = C.SQLGetDiagField(
+        C.SQL_HANDLE_DBC,
+        conn_handle,
+        1,
+        C.SQL_DIAG_CONNECTION_NAME,
+        buffer,
+        100,
+        text_len,
+    )
+    assert result == C.SQL_SUCCESS
+    assert text_len[0] == 0
+
+
+def test_connection_error_sqlstate(C, connection_refused, conn_handle):
+    buffer = C.ffi.new("SQLCHAR[]", 6)
+    text_len = C.ffi.new("SQLSMALLINT*")
+    result = C.SQLGetDiagField(
+        C.SQL_HANDLE_DBC, conn_handle, 1, C.SQL_DIAG_SQLSTATE, buffer, 6, text_len
+    )
+    assert result == C.SQL_SUCCESS
+    # The link failed, rather than the server refusing the connection
+    assert C.ffi.string(buffer) == b"08S01"
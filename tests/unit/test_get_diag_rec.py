@@ -202,3 +202,100 @@ def test_env_truncated_message_text(C, env_handle):
     assert C.ffi.string(sql_state) == b"HYC00"
     assert C.ffi.string(buffer) == b"Unsu"
     assert text_len[0] == len(b"Unsupported attribute")
+
+
+def test_env_error_w(C, env_handle):
+    assert (
+        C.SQLSetEnvAttr(env_handle, C.SQL_ATTR_CONNECTION_POOLING, C.NULL, 0)
+        == C.SQL_ERROR
+    )
+
+    sql_state = C.ffi.new("SQLWCHAR[]", 6)
+    buffer = C.ffi.new("SQLWCHAR[]", 100)
+    text_len = C.ffi.new("SQLSMALLINT*")
+    result = C.SQLGetDiagRecW(
+        C.SQL_HANDLE_ENV,
+        env_handle,
+        1,
+        sql_state,
+        C.NULL,
+        buffer,
+        len(buffer),
+        text_len,
+    )
+    assert result == C.SQL_SUCCESS
+    assert bytes(C.ffi.buffer(sql_state)) == "HYC00\0".encode("utf-16-le")
+    # Lengths are in characters
+    assert text_len[0] == len("Unsupported attribute")
+    assert (
+        bytes(C.ffi.buffer(buffer, 2 * text_len[0])).decode("utf-16-le")
+        == "Unsupported attribute"
+    )
+
+
+def test_env_error_w_truncated(C, env_handle):
+    assert (
+        C.SQLSetEnvAttr(env_handle, C.SQL_ATTR_CONNECTION_POOLING, C.NULL, 0)
+        == C.SQL_ERROR
+    )
+
+    buffer = C.ffi.new("SQLWCHAR[]", 5)
+    text_len = C.ffi.new("SQLSMALLINT*")
+    result = C.SQLGetDiagRecW(
+        C.SQL_HANDLE_ENV,
+        env_handle,
+        1,
+        C.NULL,
+        C.NULL,
+        buffer,
+        len(buffer),
+        text_len,
+    )
+    assert result == C.SQL_SUCCESS_WITH_INFO
+    assert text_len[0] == len("Unsupported attribute")
+    assert bytes(C.ffi.buffer(buffer)) == "Unsu\0".encode("utf-16-le")
+
+
+def test_env_error_cleared(C, env_handle):
+    assert (
+        C.SQLSetEnvAttr(env_handle, C.SQL_ATTR_CONNECTION_POOLING, C.NULL, 0)
+        == C.SQL_ERROR
+    )
+    # The diagnostics are those of the last function called
+    assert (
+        C.SQLSetEnvAttr(
+            env_handle,
+            C.SQL_ATTR_ODBC_VERSION,
+            C.ffi.cast("SQLPOINTER", C.SQL_OV_ODBC3),
+            0,
+        )
+        == C.SQL_SUCCESS
+    )
+
+    result = C.SQLGetDiagRec(
+        C.SQL_HANDLE_ENV, env_handle, 1, C.NULL, C.NULL, C.NULL, 0, C.NULL
+    )
+    assert result == C.SQL_NO_DATA
+
+
+def test_env_error_truncated(C, env_handle):
+    assert (
+        C.SQLSetEnvAttr(env_handle, C.SQL_ATTR_CONNECTION_POOLING, C.NULL, 0)
+        == C.SQL_ERROR
+    )
+
+    buffer = C.ffi.new("SQLCHAR[]", 3)
+    text_len = C.ffi.new("SQLSMALLINT*")
+    result = C.SQLGetDiagRec(
+        C.SQL_HANDLE_ENV,
+        env_handle,
+        1,
+        C.NULL,
+        C.NULL,
+        buffer,
+        len(buffer),
+        text_len,
+    )
+    assert result == C.SQL_SUCCESS_WITH_INFO
+    assert C.ffi.string(buffer) == b"Un"
+    assert text_len[0] == len("Unsupported attribute")
@@ -1,2 +1,70 @@
+import pytest
+
+
 def test_connect_invalid_handle(C):
     assert C.SQLGetInfo(C.NULL, 0, C.NULL, 0, C.NULL) == C.SQL_INVALID_HANDLE
+
+
+@pytest.mark.parametrize(
+    "info_type, expected",
+    [
+        ("SQL_SCROLL_OPTIONS", ["SQL_SO_FORWARD_ONLY", "SQL_SO_STATIC"]),
+        ("SQL_FORWARD_ONLY_CURSOR_ATTRIBUTES1", ["SQL_CA1_NEXT"]),
+        (
+            "SQL_STATIC_CURSOR_ATTRIBUTES1",
+            ["SQL_CA1_NEXT", "SQL_CA1_ABSOLUTE", "SQL_CA1_RELATIVE"],
+        ),
+        ("SQL_KEYSET_CURSOR_ATTRIBUTES1", []),
+        ("SQL_DYNAMIC_CURSOR_ATTRIBUTES1", []),
+    ],
+)
+def test_cursor_info(C, conn_handle, info_type, expected):
+    value = C.ffi.new("SQLUINTEGER*")
+    assert (
+        C.SQLGetInfo(conn_handle, getattr(C, info_type), value, 0, C.NULL)
+        == C.SQL_SUCCESS
+    )
+    mask = 0
+    for flag in expected:
+        mask |= getattr(C, flag)
+    assert value[0] == mask
+
+
+def test_connect_invalid_handle_w(C):
+    assert C.SQLGetInfoW(C.NULL, 0, C.NULL, 0, C.NULL) == C.SQL_INVALID_HANDLE
+
+
+def test_driver_name_w(C, conn_handle):
+    value = C.ffi.new("SQLWCHAR[]", 100)
+    length = C.ffi.new("SQLSMALLINT*")
+    assert (
+        C.SQLGetInfoW(
+            conn_handle, C.SQL_DRIVER_NAME, value, C.ffi.sizeof(value), length
+        )
+        == C.SQL_SUCCESS
+    )
+    # Lengths are in bytes
+    assert length[0] == 2 * len("kom2")
+    assert bytes(C.ffi.buffer(value, length[0])).decode("utf-16-le") == "kom2"
+
+
+def test_driver_name_w_truncated(C, conn_handle):
+    value = C.ffi.new("SQLWCHAR[]", 3)
+    length = C.ffi.new("SQLSMALLINT*")
+    assert (
+        C.SQLGetInfoW(
+            conn_handle, C.SQL_DRIVER_NAME, value, C.ffi.sizeof(value), length
+        )
+        == C.SQL_SUCCESS_WITH_INFO
+    )
+    assert length[0] == 2 * len("kom2")
+    assert bytes(C.ffi.buffer(value)) == "ko\0".encode("utf-16-le")
+
+
+def test_cursor_info_w(C, conn_handle):
+    value = C.ffi.new("SQLUINTEGER*")
+    assert (
+        C.SQLGetInfoW(conn_handle, C.SQL_SCROLL_OPTIONS, value, 0, C.NULL)
+        == C.SQL_SUCCESS
+    )
+    assert value[0] == C.SQL_SO_FORWARD_ONLY | C.SQL_SO_STATIC
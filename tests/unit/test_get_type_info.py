@@ -0,0 +1,50 @@
+import pytest
+
+
+def test_invalid_handle(C):
+    assert C.SQLGetTypeInfo(C.NULL, C.SQL_ALL_TYPES) == C.SQL_INVALID_HANDLE
+
+
+def fetch_types(C, stmt_handle):
+    types = []
+    name = C.ffi.new("SQLCHAR[]", 100)
+    data_type = C.ffi.new("SQLSMALLINT*")
+    length = C.ffi.new("SQLLEN*")
+    while C.SQLFetch(stmt_handle) == C.SQL_SUCCESS:
+        assert (
+            C.SQLGetData(stmt_handle, 1, C.SQL_C_CHAR, name, len(name), length)
+            == C.SQL_SUCCESS
+        )
+        assert (
+            C.SQLGetData(stmt_handle, 2, C.SQL_C_SSHORT, data_type, 0, length)
+            == C.SQL_SUCCESS
+        )
+        types.append((C.ffi.string(name), data_type[0]))
+    return types
+
+
+@pytest.mark.parametrize(
+    "data_type, expected",
+    [
+        (
+            "SQL_ALL_TYPES",
+            [
+                (b"BIGINT", "SQL_BIGINT"),
+                (b"INTEGER", "SQL_INTEGER"),
+                (b"SMALLINT", "SQL_SMALLINT"),
+                (b"DOUBLE", "SQL_DOUBLE"),
+                (b"VARCHAR", "SQL_VARCHAR"),
+                (b"DATE", "SQL_TYPE_DATE"),
+                (b"TIMESTAMP", "SQL_TYPE_TIMESTAMP"),
+            ],
+        ),
+        ("SQL_VARCHAR", [(b"VARCHAR", "SQL_VARCHAR")]),
+        ("SQL_TIMESTAMP", [(b"TIMESTAMP", "SQL_TYPE_TIMESTAMP")]),
+        ("SQL_CHAR", []),
+    ],
+)
+def test_types(C, stmt_handle, data_type, expected):
+    assert C.SQLGetTypeInfo(stmt_handle, getattr(C, data_type)) == C.SQL_SUCCESS
+    assert fetch_types(C, stmt_handle) == [
+        (name, getattr(C, type_name)) for name, type_name in expected
+    ]
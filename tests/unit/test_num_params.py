@@ -0,0 +1,25 @@
+import pytest
+
+
+def test_invalid_handle(C):
+    assert C.SQLNumParams(C.NULL, C.NULL) == C.SQL_INVALID_HANDLE
+
+
+def test_not_prepared(C, stmt_handle):
+    count = C.ffi.new("SQLSMALLINT *")
+    assert C.SQLNumParams(stmt_handle, count) == C.SQL_ERROR
+
+
+@pytest.mark.parametrize(
+    "statement, expected",
+    [
+        (b"SELECT * FROM Resistors", 0),
+        (b"SELECT * FROM Resistors WHERE pk = ?", 1),
+        (b"SELECT ? FROM Resistors WHERE IPN = ? OR name LIKE ?", 3),
+    ],
+)
+def test_num_params(C, stmt_handle, statement, expected):
+    count = C.ffi.new("SQLSMALLINT *")
+    assert C.SQLPrepare(stmt_handle, statement, len(statement)) == C.SQL_SUCCESS
+    assert C.SQLNumParams(stmt_handle, count) == C.SQL_SUCCESS
+    assert count[0] == expected
@@ -1,2 +1,22 @@
 def test_invalid_handle(C):
     assert C.SQLPrepare(C.NULL, C.NULL, 0) == C.SQL_INVALID_HANDLE
+
+
+def test_invalid_handle_w(C):
+    assert C.SQLPrepareW(C.NULL, C.NULL, 0) == C.SQL_INVALID_HANDLE
+
+
+def test_failed_prepare_unprepares(C, stmt_handle):
+    statement = b"SELECT * FROM Resistors"
+    assert C.SQLPrepare(stmt_handle, statement, len(statement)) == C.SQL_SUCCESS
+    statement = b"SELECT FROM"
+    assert C.SQLPrepare(stmt_handle, statement, len(statement)) == C.SQL_ERROR
+
+    # The previous statement isn't left to be executed instead
+    assert C.SQLExecute(stmt_handle) == C.SQL_ERROR
+    sql_state = C.ffi.new("SQLCHAR[]", 6)
+    result = C.SQLGetDiagRec(
+        C.SQL_HANDLE_STMT, stmt_handle, 1, sql_state, C.NULL, C.NULL, 0, C.NULL
+    )
+    assert result == C.SQL_SUCCESS
+    assert C.ffi.string(sql_state) == b"HY010"
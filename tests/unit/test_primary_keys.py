@@ -0,0 +1,20 @@
+def test_invalid_handle(C):
+    assert (
+        C.SQLPrimaryKeys(C.NULL, C.NULL, 0, C.NULL, 0, C.NULL, 0)
+        == C.SQL_INVALID_HANDLE
+    )
+
+
+def test_null_table_name(C, stmt_handle):
+    assert (
+        C.SQLPrimaryKeys(stmt_handle, C.NULL, 0, C.NULL, 0, C.NULL, 0) == C.SQL_ERROR
+    )
+    sql_state = C.ffi.new("SQLCHAR[]", 6)
+    text_len = C.ffi.new("SQLSMALLINT*")
+    assert (
+        C.SQLGetDiagRec(
+            C.SQL_HANDLE_STMT, stmt_handle, 1, sql_state, C.NULL, C.NULL, 0, text_len
+        )
+        == C.SQL_SUCCESS
+    )
+    assert C.ffi.string(sql_state) == b"HY009"
@@ -1,2 +1,113 @@
+import pytest
+
+
 def test_invalid_handle(C):
     assert C.SQLSetStmtAttr(C.NULL, 0, C.NULL, 0) == C.SQL_INVALID_HANDLE
+
+
+def test_paramset_size(C, stmt_handle):
+    assert (
+        C.SQLSetStmtAttr(
+            stmt_handle, C.SQL_ATTR_PARAMSET_SIZE, C.ffi.cast("SQLPOINTER", 100), 0
+        )
+        == C.SQL_SUCCESS
+    )
+    value = C.ffi.new("SQLULEN*")
+    assert (
+        C.SQLGetStmtAttr(stmt_handle, C.SQL_ATTR_PARAMSET_SIZE, value, 0, C.NULL)
+        == C.SQL_SUCCESS
+    )
+    assert value[0] == 100
+
+
+def test_paramset_size_zero(C, stmt_handle):
+    assert (
+        C.SQLSetStmtAttr(
+            stmt_handle, C.SQL_ATTR_PARAMSET_SIZE, C.ffi.cast("SQLPOINTER", 0), 0
+        )
+        == C.SQL_ERROR
+    )
+
+
+@pytest.mark.parametrize(
+    "attr, value",
+    [
+        ("SQL_ATTR_ROW_ARRAY_SIZE", 10),
+        ("SQL_ATTR_ROW_BIND_TYPE", 64),
+    ],
+)
+def test_row_attrs(C, stmt_handle, attr, value):
+    attr = getattr(C, attr)
+    assert (
+        C.SQLSetStmtAttr(stmt_handle, attr, C.ffi.cast("SQLPOINTER", value), 0)
+        == C.SQL_SUCCESS
+    )
+    result = C.ffi.new("SQLULEN*")
+    assert C.SQLGetStmtAttr(stmt_handle, attr, result, 0, C.NULL) == C.SQL_SUCCESS
+    assert result[0] == value
+
+
+def test_row_array_size_zero(C, stmt_handle):
+    assert (
+        C.SQLSetStmtAttr(
+            stmt_handle, C.SQL_ATTR_ROW_ARRAY_SIZE, C.ffi.cast("SQLPOINTER", 0), 0
+        )
+        == C.SQL_ERROR
+    )
+
+
+@pytest.mark.parametrize(
+    "cursor_type, ret, expected",
+    [
+        ("SQL_CURSOR_FORWARD_ONLY", "SQL_SUCCESS", "SQL_CURSOR_FORWARD_ONLY"),
+        ("SQL_CURSOR_STATIC", "SQL_SUCCESS", "SQL_CURSOR_STATIC"),
+        ("SQL_CURSOR_KEYSET_DRIVEN", "SQL_SUCCESS_WITH_INFO", "SQL_CURSOR_STATIC"),
+        ("SQL_CURSOR_DYNAMIC", "SQL_SUCCESS_WITH_INFO", "SQL_CURSOR_STATIC"),
+    ],
+)
+def test_cursor_type(C, stmt_handle, cursor_type, ret, expected):
+    assert (
+        C.SQLSetStmtAttr(
+            stmt_handle,
+            C.SQL_ATTR_CURSOR_TYPE,
+            C.ffi.cast("SQLPOINTER", getattr(C, cursor_type)),
+            0,
+        )
+        == getattr(C, ret)
+    )
+    value = C.ffi.new("SQLULEN*")
+    assert (
+        C.SQLGetStmtAttr(stmt_handle, C.SQL_ATTR_CURSOR_TYPE, value, 0, C.NULL)
+        == C.SQL_SUCCESS
+    )
+    assert value[0] == getattr(C, expected)
+
+
+@pytest.mark.parametrize(
+    "scrollable, cursor_type",
+    [
+        ("SQL_SCROLLABLE", "SQL_CURSOR_STATIC"),
+        ("SQL_NONSCROLLABLE", "SQL_CURSOR_FORWARD_ONLY"),
+    ],
+)
+def test_cursor_scrollable(C, stmt_handle, scrollable, cursor_type):
+    assert (
+        C.SQLSetStmtAttr(
+            stmt_handle,
+            C.SQL_ATTR_CURSOR_SCROLLABLE,
+            C.ffi.cast("SQLPOINTER", getattr(C, scrollable)),
+            0,
+        )
+        == C.SQL_SUCCESS
+    )
+    value = C.ffi.new("SQLULEN*")
+    assert (
+        C.SQLGetStmtAttr(stmt_handle, C.SQL_ATTR_CURSOR_SCROLLABLE, value, 0, C.NULL)
+        == C.SQL_SUCCESS
+    )
+    assert value[0] == getattr(C, scrollable)
+    assert (
+        C.SQLGetStmtAttr(stmt_handle, C.SQL_ATTR_CURSOR_TYPE, value, 0, C.NULL)
+        == C.SQL_SUCCESS
+    )
+    assert value[0] == getattr(C, cursor_type)
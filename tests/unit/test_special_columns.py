@@ -0,0 +1,44 @@
+import pytest
+
+
+def test_invalid_handle(C):
+    assert (
+        C.SQLSpecialColumns(C.NULL, 0, C.NULL, 0, C.NULL, 0, C.NULL, 0, 0, 0)
+        == C.SQL_INVALID_HANDLE
+    )
+
+
+@pytest.mark.parametrize(
+    "identifier_type, scope, nullable, expected",
+    [
+        (5, 0, 0, b"HY097"),
+        (1, 5, 0, b"HY098"),
+        (1, 0, 5, b"HY099"),
+    ],
+)
+def test_invalid_options(C, stmt_handle, identifier_type, scope, nullable, expected):
+    table = b"Resistors"
+    assert (
+        C.SQLSpecialColumns(
+            stmt_handle,
+            identifier_type,
+            C.NULL,
+            0,
+            C.NULL,
+            0,
+            table,
+            len(table),
+            scope,
+            nullable,
+        )
+        == C.SQL_ERROR
+    )
+    sql_state = C.ffi.new("SQLCHAR[]", 6)
+    text_len = C.ffi.new("SQLSMALLINT*")
+    assert (
+        C.SQLGetDiagRec(
+            C.SQL_HANDLE_STMT, stmt_handle, 1, sql_state, C.NULL, C.NULL, 0, text_len
+        )
+        == C.SQL_SUCCESS
+    )
+    assert C.ffi.string(sql_state) == expected
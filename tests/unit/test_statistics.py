@@ -0,0 +1,34 @@
+import pytest
+
+
+def test_invalid_handle(C):
+    assert (
+        C.SQLStatistics(C.NULL, C.NULL, 0, C.NULL, 0, C.NULL, 0, 0, 0)
+        == C.SQL_INVALID_HANDLE
+    )
+
+
+@pytest.mark.parametrize(
+    "unique, reserved, expected",
+    [
+        (5, 0, b"HY100"),
+        (0, 5, b"HY101"),
+    ],
+)
+def test_invalid_options(C, stmt_handle, unique, reserved, expected):
+    table = b"Resistors"
+    assert (
+        C.SQLStatistics(
+            stmt_handle, C.NULL, 0, C.NULL, 0, table, len(table), unique, reserved
+        )
+        == C.SQL_ERROR
+    )
+    sql_state = C.ffi.new("SQLCHAR[]", 6)
+    text_len = C.ffi.new("SQLSMALLINT*")
+    assert (
+        C.SQLGetDiagRec(
+            C.SQL_HANDLE_STMT, stmt_handle, 1, sql_state, C.NULL, C.NULL, 0, text_len
+        )
+        == C.SQL_SUCCESS
+    )
+    assert C.ffi.string(sql_state) == expected